@@ -0,0 +1,60 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScheduleCalendarSpec defines schedules shared by ScheduledScalers which refer the calendar
+type ScheduleCalendarSpec struct {
+	// TimeZone is used by ScheduledScalers which have no time zone
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// +optional
+	Schedule []Schedule `json:"schedule,omitempty"`
+	// +optional
+	Default *Schedule `json:"default,omitempty"`
+	// +optional
+	Blackout *Blackout `json:"blackout,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=schedulecalendars,scope=Namespaced,shortName=scal
+// +kubebuilder:printcolumn:name="TIMEZONE",type=string,JSONPath=`.spec.timeZone`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ScheduleCalendar is the Schema for the schedulecalendars API
+type ScheduleCalendar struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ScheduleCalendarSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ScheduleCalendarList contains a list of ScheduleCalendar
+type ScheduleCalendarList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ScheduleCalendar `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ScheduleCalendar{}, &ScheduleCalendarList{})
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package v1
 
 import (
+	autov2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -24,16 +26,154 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 type SchedulingTarget struct {
-	Name string `json:"name"`
+	// Kind of the target workload. Deployment is used when it's empty.
+	// Any kind which serves scale subresource can be a target
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// APIVersion of the target workload. apps/v1 is used when it's empty
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+	// Name of the target workload. Either name or selector must be set
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Selector selects every workload of the kind in the namespace as targets.
+	// Matching workloads are resolved whenever a schedule runs
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 }
 
 type Schedule struct {
+	// Name of the schedule. Replicas of a schedule in the calendar can be overridden by its name
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Type is fixed or range. It's inferred from replicas, or minReplicas and maxReplicas by the defaulting webhook when it's empty
 	// +kubebuilder:validation:Enum:=fixed;range
-	Type        string `json:"type"`
-	Runat       string `json:"runat"`
+	// +optional
+	Type string `json:"type,omitempty"`
+	// Runat is the cron expression when the schedule is applied. One of runat, start and at must be set
+	// +optional
+	Runat string `json:"runat,omitempty"`
+	// Start is the cron expression when the time window of the schedule starts.
+	// The schedule is applied during the window, and spec.default is applied after every window ends
+	// +optional
+	Start string `json:"start,omitempty"`
+	// End is the cron expression when the time window ends. Either end or duration must be set with start
+	// +optional
+	End string `json:"end,omitempty"`
+	// Duration is the length of the time window from start
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// At is the datetime when the one-off schedule is applied. With until or duration, it's the start of a one-off time window.
+	// It's never applied again after the time passes
+	// +optional
+	At *metav1.Time `json:"at,omitempty"`
+	// Until is the datetime when the one-off time window from at ends
+	// +optional
+	Until *metav1.Time `json:"until,omitempty"`
+	// Priority decides which schedule is applied when schedules collide.
+	// Among active windows, the highest priority wins, and among schedules due at the same time, the highest priority wins
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	Replicas    *int32 `json:"replicas,omitempty"`
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+	// Metrics of HPA created by range schedule. spec.metrics is used when it's empty
+	// +optional
+	Metrics []autov2beta2.MetricSpec `json:"metrics,omitempty"`
+	// Behavior of HPA created by range schedule, such as stabilization windows and scaling policies.
+	// Cluster defaults are used when it's empty
+	// +optional
+	Behavior *autov2beta2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+}
+
+// CalendarReference refers a ScheduleCalendar in the same namespace
+type CalendarReference struct {
+	Name string `json:"name"`
+	// Overrides replace replicas of schedules in the calendar for this ScheduledScaler
+	// +optional
+	Overrides []ScheduleOverride `json:"overrides,omitempty"`
+}
+
+// ScheduleOverride replaces replicas of the schedule with the name in the calendar. Fields which are not set are kept
+type ScheduleOverride struct {
+	Name        string `json:"name"`
+	Replicas    *int32 `json:"replicas,omitempty"`
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+}
+
+// Blackout is a calendar of dates when scaling is suppressed.
+// Each date is "2006-01-02", or an inclusive range such as "2006-01-02/2006-01-03", in the time zone of the ScheduledScaler
+type Blackout struct {
+	// +optional
+	Dates []string `json:"dates,omitempty"`
+	// ConfigMapRef refers a ConfigMap in the same namespace. Every value of its data has a date or range per line
+	// +optional
+	ConfigMapRef *corev1.LocalObjectReference `json:"configMapRef,omitempty"`
+}
+
+// DeletionPolicyType is how targets are handled when ScheduledScaler is deleted
+type DeletionPolicyType string
+
+const (
+	// DeletionPolicyKeep keeps targets at current replicas
+	DeletionPolicyKeep = DeletionPolicyType("Keep")
+	// DeletionPolicyRestore restores targets to replicas recorded when ScheduledScaler is reconciled at first
+	DeletionPolicyRestore = DeletionPolicyType("Restore")
+	// DeletionPolicyScale scales targets to the given replicas
+	DeletionPolicyScale = DeletionPolicyType("Scale")
+)
+
+type DeletionPolicy struct {
+	// +kubebuilder:validation:Enum:=Keep;Restore;Scale
+	Type DeletionPolicyType `json:"type"`
+	// Replicas to scale targets to. It's required only for Scale type
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+}
+
+// TargetReplicas is replicas of a target workload
+type TargetReplicas struct {
+	Name     string `json:"name"`
+	Replicas int32  `json:"replicas"`
+}
+
+// ScheduleResult is the result of the last run of a schedule
+type ScheduleResult string
+
+const (
+	ScheduleResultSucceeded = ScheduleResult("Succeeded")
+	ScheduleResultFailed    = ScheduleResult("Failed")
+)
+
+// ScheduleStatus is the observed state of a schedule entry
+type ScheduleStatus struct {
+	// Index of the schedule. Schedules of the calendar are counted first, and -1 is spec.default
+	Index int32  `json:"index"`
+	Name  string `json:"name,omitempty"`
+	// NextRunTime is when the schedule is applied next. The default is applied next when the earliest time window ends
+	// +optional
+	NextRunTime *metav1.Time `json:"nextRunTime,omitempty"`
+	// +optional
+	LastRunTime *metav1.Time `json:"lastRunTime,omitempty"`
+	// LastScheduleTime is when the schedule applied by the last run was due.
+	// A missed schedule due at or before it isn't replayed when the operator is restarted
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// +optional
+	LastResult ScheduleResult `json:"lastResult,omitempty"`
+	// LastError is the error of the last run when it's failed
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+	// Replicas, MinReplicas and MaxReplicas are applied by the last run
+	// +optional
 	Replicas    *int32 `json:"replicas,omitempty"`
 	MinReplicas *int32 `json:"minReplicas,omitempty"`
 	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+	// HpaSpecHash is the hash of metrics and behavior applied by the last run. It's empty when neither is set
+	// +optional
+	HpaSpecHash string `json:"hpaSpecHash,omitempty"`
 }
 
 // ScheduledScalerSpec defines the desired state of ScheduledScaler
@@ -43,7 +183,37 @@ type ScheduledScalerSpec struct {
 
 	TimeZone string           `json:"timeZone,omitempty"`
 	Target   SchedulingTarget `json:"target"`
-	Schedule []Schedule       `json:"schedule"`
+	// +optional
+	Schedule []Schedule `json:"schedule,omitempty"`
+	// CalendarRef refers a ScheduleCalendar whose schedules, default and blackout are merged into this ScheduledScaler.
+	// Schedules of the calendar come before spec.schedule, and spec.default and spec.timeZone take precedence over the calendar
+	// +optional
+	CalendarRef *CalendarReference `json:"calendarRef,omitempty"`
+	// Default is applied when no time window is active. It has no runat, start, end and duration
+	// +optional
+	Default *Schedule `json:"default,omitempty"`
+	// Metrics of HPA created by every range schedule.
+	// HPA targets 50% of CPU utilization when neither spec.metrics nor schedule.metrics is set
+	// +optional
+	Metrics []autov2beta2.MetricSpec `json:"metrics,omitempty"`
+	// HpaRef refers an existing HPA of the target in the same namespace.
+	// When it's set, schedules only patch minReplicas/maxReplicas of the HPA instead of creating a new one,
+	// and original bounds of the HPA are restored when the ScheduledScaler is removed
+	// +optional
+	HpaRef *corev1.LocalObjectReference `json:"hpaRef,omitempty"`
+	// StartingDeadlineSeconds is how old a missed schedule can be to be applied when the operator is restarted
+	// or the ScheduledScaler is changed. The most recently due schedule is always applied when it's empty,
+	// and nothing is applied until the next schedule when it's 0
+	// +kubebuilder:validation:Minimum:=0
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// Blackout suppresses scaling on the dates, such as public holidays.
+	// The desired schedule is applied when the blackout ends
+	// +optional
+	Blackout *Blackout `json:"blackout,omitempty"`
+	// DeletionPolicy decides replicas of targets after ScheduledScaler is deleted. Keep is used when it's empty
+	// +optional
+	DeletionPolicy *DeletionPolicy `json:"deletionPolicy,omitempty"`
 }
 
 // ScheduledScalerStatus defines the observed state of ScheduledScaler
@@ -51,14 +221,32 @@ type ScheduledScalerStatus struct {
 	Phase   Status `json:"phase,omitempty"`
 	Message string `json:"message,omitempty"`
 	Reason  Reason `json:"reason,omitempty"`
+	// OriginalReplicas is replicas of targets recorded when ScheduledScaler is reconciled at first
+	OriginalReplicas []TargetReplicas `json:"originalReplicas,omitempty"`
+	// Blackouts is dates when scaling is suppressed, resolved from spec.blackout
+	Blackouts []string `json:"blackouts,omitempty"`
+	// ExpiredSchedules is indexes of one-off schedules whose time has passed. Schedules of the calendar are counted first
+	ExpiredSchedules []int32 `json:"expiredSchedules,omitempty"`
+	// NextRunTime is the earliest next run time of every schedule
+	NextRunTime *metav1.Time `json:"nextRunTime,omitempty"`
+	// Schedules is the state of each schedule entry
+	Schedules []ScheduleStatus `json:"schedules,omitempty"`
+	// ObservedGeneration is the generation of the spec which crons are running with
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions are Ready, SpecValid, TargetFound, HPAReady and LastScaleSucceeded
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:path=scheduledscalers,scope=Namespaced,shortName=scsc
 // +kubebuilder:printcolumn:name="TARGET",type=string,JSONPath=`.spec.target.name`
+// +kubebuilder:printcolumn:name="READY",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // +kubebuilder:printcolumn:name="STATUS",type=string,JSONPath=`.status.phase`
 // +kubebuilder:printcolumn:name="REASON",type=string,JSONPath=`.status.reason`
+// +kubebuilder:printcolumn:name="NEXT RUN",type=string,JSONPath=`.status.nextRunTime`
 // +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // ScheduledScaler is the Schema for the scheduledscalers API
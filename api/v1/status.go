@@ -1,15 +1,21 @@
 package v1
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 type Status string
 type Reason string
 
 const (
+	// Deprecated: StatusUpdating isn't set since reconciling is done in a single pass
 	StatusUpdating = Status("Updating")
 	StatusRunning  = Status("Running")
 	StatusFailed   = Status("Failed")
 )
 
 const (
+	// Deprecated: NeedToReconcile isn't set since reconciling is done in a single pass
 	NeedToReconcile = Reason("Updating")
 	ReconcileDone   = Reason("Done")
 )
@@ -18,3 +24,57 @@ const (
 	InternalLogicError    = Reason("InternalLogicError")
 	ValidationFailedError = Reason("InvalidSpecError")
 )
+
+// ConditionStatus is status of a condition, one of True, False and Unknown
+type ConditionStatus string
+
+const (
+	ConditionTrue    = ConditionStatus("True")
+	ConditionFalse   = ConditionStatus("False")
+	ConditionUnknown = ConditionStatus("Unknown")
+)
+
+// Types of conditions of ScheduledScaler
+const (
+	// ConditionReady is true when crons of the ScheduledScaler are running with a valid spec and found targets
+	ConditionReady = "Ready"
+	// ConditionSpecValid is true when the spec is valid
+	ConditionSpecValid = "SpecValid"
+	// ConditionTargetFound is true when every target exists
+	ConditionTargetFound = "TargetFound"
+	// ConditionHPAReady is true when the HPA referred by spec.hpaRef exists, or when HPAs are created by range schedules
+	ConditionHPAReady = "HPAReady"
+	// ConditionLastScaleSucceeded is true when the last run of schedules succeeded
+	ConditionLastScaleSucceeded = "LastScaleSucceeded"
+)
+
+// Reasons of conditions of ScheduledScaler
+const (
+	ReasonReconciling      = "Reconciling"
+	ReasonReconciled       = "Reconciled"
+	ReasonInternalError    = "InternalError"
+	ReasonValidationPassed = "ValidationPassed"
+	ReasonValidationFailed = "ValidationFailed"
+	ReasonTargetFound      = "TargetFound"
+	ReasonTargetNotFound   = "TargetNotFound"
+	ReasonHPAFound         = "HPAFound"
+	ReasonHPANotFound      = "HPANotFound"
+	ReasonHPAManaged       = "HPAManaged"
+	ReasonScaleSucceeded   = "ScaleSucceeded"
+	ReasonScaleFailed      = "ScaleFailed"
+	ReasonNotScaledYet     = "NotScaledYet"
+)
+
+// Condition is an observed state of ScheduledScaler. It has the same schema as metav1.Condition of newer Kubernetes
+type Condition struct {
+	Type string `json:"type"`
+	// +kubebuilder:validation:Enum:=True;False;Unknown
+	Status ConditionStatus `json:"status"`
+	// ObservedGeneration is the generation of the ScheduledScaler which the condition was set based upon
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastTransitionTime is when the status of the condition changed last
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+	Reason             string      `json:"reason"`
+	Message            string      `json:"message"`
+}
@@ -22,12 +22,111 @@ limitations under the License.
 package v1
 
 import (
+	"k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Blackout) DeepCopyInto(out *Blackout) {
+	*out = *in
+	if in.Dates != nil {
+		in, out := &in.Dates, &out.Dates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Blackout.
+func (in *Blackout) DeepCopy() *Blackout {
+	if in == nil {
+		return nil
+	}
+	out := new(Blackout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalendarReference) DeepCopyInto(out *CalendarReference) {
+	*out = *in
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = make([]ScheduleOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalendarReference.
+func (in *CalendarReference) DeepCopy() *CalendarReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CalendarReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Condition) DeepCopyInto(out *Condition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
+func (in *Condition) DeepCopy() *Condition {
+	if in == nil {
+		return nil
+	}
+	out := new(Condition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeletionPolicy) DeepCopyInto(out *DeletionPolicy) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeletionPolicy.
+func (in *DeletionPolicy) DeepCopy() *DeletionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DeletionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Schedule) DeepCopyInto(out *Schedule) {
 	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.At != nil {
+		in, out := &in.At, &out.At
+		*out = (*in).DeepCopy()
+	}
+	if in.Until != nil {
+		in, out := &in.Until, &out.Until
+		*out = (*in).DeepCopy()
+	}
 	if in.Replicas != nil {
 		in, out := &in.Replicas, &out.Replicas
 		*out = new(int32)
@@ -43,6 +142,18 @@ func (in *Schedule) DeepCopyInto(out *Schedule) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]v2beta2.MetricSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Behavior != nil {
+		in, out := &in.Behavior, &out.Behavior
+		*out = new(v2beta2.HorizontalPodAutoscalerBehavior)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Schedule.
@@ -55,13 +166,175 @@ func (in *Schedule) DeepCopy() *Schedule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleCalendar) DeepCopyInto(out *ScheduleCalendar) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleCalendar.
+func (in *ScheduleCalendar) DeepCopy() *ScheduleCalendar {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleCalendar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScheduleCalendar) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleCalendarList) DeepCopyInto(out *ScheduleCalendarList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ScheduleCalendar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleCalendarList.
+func (in *ScheduleCalendarList) DeepCopy() *ScheduleCalendarList {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleCalendarList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScheduleCalendarList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleCalendarSpec) DeepCopyInto(out *ScheduleCalendarSpec) {
+	*out = *in
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = make([]Schedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(Schedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Blackout != nil {
+		in, out := &in.Blackout, &out.Blackout
+		*out = new(Blackout)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleCalendarSpec.
+func (in *ScheduleCalendarSpec) DeepCopy() *ScheduleCalendarSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleCalendarSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleOverride) DeepCopyInto(out *ScheduleOverride) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleOverride.
+func (in *ScheduleOverride) DeepCopy() *ScheduleOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleStatus) DeepCopyInto(out *ScheduleStatus) {
+	*out = *in
+	if in.NextRunTime != nil {
+		in, out := &in.NextRunTime, &out.NextRunTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRunTime != nil {
+		in, out := &in.LastRunTime, &out.LastRunTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleStatus.
+func (in *ScheduleStatus) DeepCopy() *ScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScheduledScaler) DeepCopyInto(out *ScheduledScaler) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledScaler.
@@ -117,7 +390,7 @@ func (in *ScheduledScalerList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScheduledScalerSpec) DeepCopyInto(out *ScheduledScalerSpec) {
 	*out = *in
-	out.Target = in.Target
+	in.Target.DeepCopyInto(&out.Target)
 	if in.Schedule != nil {
 		in, out := &in.Schedule, &out.Schedule
 		*out = make([]Schedule, len(*in))
@@ -125,6 +398,43 @@ func (in *ScheduledScalerSpec) DeepCopyInto(out *ScheduledScalerSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CalendarRef != nil {
+		in, out := &in.CalendarRef, &out.CalendarRef
+		*out = new(CalendarReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(Schedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]v2beta2.MetricSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HpaRef != nil {
+		in, out := &in.HpaRef, &out.HpaRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Blackout != nil {
+		in, out := &in.Blackout, &out.Blackout
+		*out = new(Blackout)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeletionPolicy != nil {
+		in, out := &in.DeletionPolicy, &out.DeletionPolicy
+		*out = new(DeletionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledScalerSpec.
@@ -140,6 +450,39 @@ func (in *ScheduledScalerSpec) DeepCopy() *ScheduledScalerSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScheduledScalerStatus) DeepCopyInto(out *ScheduledScalerStatus) {
 	*out = *in
+	if in.OriginalReplicas != nil {
+		in, out := &in.OriginalReplicas, &out.OriginalReplicas
+		*out = make([]TargetReplicas, len(*in))
+		copy(*out, *in)
+	}
+	if in.Blackouts != nil {
+		in, out := &in.Blackouts, &out.Blackouts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExpiredSchedules != nil {
+		in, out := &in.ExpiredSchedules, &out.ExpiredSchedules
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.NextRunTime != nil {
+		in, out := &in.NextRunTime, &out.NextRunTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]ScheduleStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledScalerStatus.
@@ -155,6 +498,11 @@ func (in *ScheduledScalerStatus) DeepCopy() *ScheduledScalerStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SchedulingTarget) DeepCopyInto(out *SchedulingTarget) {
 	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingTarget.
@@ -166,3 +514,18 @@ func (in *SchedulingTarget) DeepCopy() *SchedulingTarget {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetReplicas) DeepCopyInto(out *TargetReplicas) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetReplicas.
+func (in *TargetReplicas) DeepCopy() *TargetReplicas {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetReplicas)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,94 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/cron"
+)
+
+// BlackoutReconciler reconciles a ConfigMap object. It rebuilds crons of ScheduledScalers whose blackout refers the ConfigMap
+type BlackoutReconciler struct {
+	client.Client
+	Log         logr.Logger
+	Scheme      *runtime.Scheme
+	CronManager cron.CronManager
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+
+func (r *BlackoutReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("configmap", req.NamespacedName)
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, req.NamespacedName, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			// ScheduledScalers keep their crons until they're updated, because deleted ConfigMap can't be resolved
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Unable to fetch resource ConfigMap")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.CronManager.UpdateBlackout(configMap.Namespace, configMap.Name); err != nil {
+		log.Error(err, "Couldn't rebuild crons of ScheduledScalers referring the ConfigMap")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// blackoutPredicate passes only ConfigMaps referred as blackout dates by ScheduledScalers.
+// Deleted ConfigMaps are ignored, because ScheduledScalers keep their crons
+func (r *BlackoutReconciler) blackoutPredicate() predicate.Funcs {
+	refers := func(meta metav1.Object) bool {
+		return r.CronManager.RefersBlackout(meta.GetNamespace(), meta.GetName())
+	}
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return refers(e.Meta)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return refers(e.MetaNew)
+		},
+		DeleteFunc: func(event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return refers(e.Meta)
+		},
+	}
+}
+
+func (r *BlackoutReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.ConfigMap{}, builder.WithPredicates(r.blackoutPredicate())).
+		Complete(r)
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/test"
+	cronFake "github.com/tmax-cloud/scheduled-scaler-operator/pkg/cron/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	cRuntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+func TestBlackoutController_Reconcile(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(s))
+
+	tc := map[string]struct {
+		configMap         *corev1.ConfigMap
+		isBlackoutUpdated bool
+	}{
+		"ConfigMap is changed": {
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "holidays",
+					Namespace: "test-ns",
+				},
+				Data: map[string]string{
+					"dates": "2026-12-25",
+				},
+			},
+			isBlackoutUpdated: true,
+		},
+		"ConfigMap is deleted": {
+			isBlackoutUpdated: false,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test cases
+			fakeCli := fake.NewFakeClientWithScheme(s)
+			if c.configMap != nil {
+				require.NoError(t, fakeCli.Create(context.Background(), c.configMap))
+			}
+
+			// mocking cron manager
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockCronManager := cronFake.NewMockCronManager(ctrl)
+			blackoutUpdated := false
+			if c.isBlackoutUpdated {
+				mockCronManager.EXPECT().UpdateBlackout("test-ns", "holidays").DoAndReturn(func(string, string) error {
+					blackoutUpdated = true
+					return nil
+				})
+			}
+
+			testController := &BlackoutReconciler{
+				Client:      fakeCli,
+				Log:         &test.FakeLogger{},
+				Scheme:      s,
+				CronManager: mockCronManager,
+			}
+
+			req := cRuntime.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: "test-ns",
+					Name:      "holidays",
+				},
+			}
+
+			// do testing function
+			_, err := testController.Reconcile(req)
+
+			// verify
+			require.NoError(t, err)
+			require.Equal(t, c.isBlackoutUpdated, blackoutUpdated)
+		})
+	}
+}
+
+func TestBlackoutController_Predicate(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "holidays",
+			Namespace: "test-ns",
+		},
+	}
+	tc := map[string]struct {
+		refers   bool
+		filter   func(predicate.Funcs) bool
+		expected bool
+	}{
+		"referred ConfigMap is created": {
+			refers: true,
+			filter: func(p predicate.Funcs) bool {
+				return p.Create(event.CreateEvent{Meta: configMap, Object: configMap})
+			},
+			expected: true,
+		},
+		"referred ConfigMap is updated": {
+			refers: true,
+			filter: func(p predicate.Funcs) bool {
+				return p.Update(event.UpdateEvent{MetaOld: configMap, ObjectOld: configMap, MetaNew: configMap, ObjectNew: configMap})
+			},
+			expected: true,
+		},
+		"other ConfigMap is updated": {
+			refers: false,
+			filter: func(p predicate.Funcs) bool {
+				return p.Update(event.UpdateEvent{MetaOld: configMap, ObjectOld: configMap, MetaNew: configMap, ObjectNew: configMap})
+			},
+			expected: false,
+		},
+		"referred ConfigMap is deleted": {
+			filter: func(p predicate.Funcs) bool {
+				return p.Delete(event.DeleteEvent{Meta: configMap, Object: configMap})
+			},
+			expected: false,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test cases
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockCronManager := cronFake.NewMockCronManager(ctrl)
+			mockCronManager.EXPECT().RefersBlackout("test-ns", "holidays").Return(c.refers).AnyTimes()
+			testController := &BlackoutReconciler{CronManager: mockCronManager}
+
+			// do testing function
+			passed := c.filter(testController.blackoutPredicate())
+
+			// verify
+			require.Equal(t, c.expected, passed)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/cron"
+)
+
+// ScheduleCalendarReconciler reconciles a ScheduleCalendar object. It rebuilds crons of ScheduledScalers which refer the calendar
+type ScheduleCalendarReconciler struct {
+	client.Client
+	Log         logr.Logger
+	Scheme      *runtime.Scheme
+	CronManager cron.CronManager
+}
+
+// +kubebuilder:rbac:groups=tmax.io,resources=schedulecalendars,verbs=get;list;watch
+
+func (r *ScheduleCalendarReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("schedulecalendar", req.NamespacedName)
+
+	calendar := &scscv1.ScheduleCalendar{}
+	if err := r.Get(ctx, req.NamespacedName, calendar); err != nil {
+		if errors.IsNotFound(err) {
+			// ScheduledScalers keep their crons until they're updated, because deleted calendar can't be resolved
+			log.Info("ScheduleCalendar is deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Unable to fetch resource ScheduleCalendar")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.CronManager.UpdateCalendar(calendar.Namespace, calendar.Name); err != nil {
+		log.Error(err, "Couldn't rebuild crons of ScheduledScalers referring the calendar")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *ScheduleCalendarReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&scscv1.ScheduleCalendar{}).
+		Complete(r)
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/test"
+	cronFake "github.com/tmax-cloud/scheduled-scaler-operator/pkg/cron/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	cRuntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestScheduleCalendarController_Reconcile(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+
+	tc := map[string]struct {
+		calendar          *scscv1.ScheduleCalendar
+		isCalendarUpdated bool
+	}{
+		"calendar is changed": {
+			calendar: &scscv1.ScheduleCalendar{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-calendar",
+					Namespace: "test-ns",
+				},
+			},
+			isCalendarUpdated: true,
+		},
+		"calendar is deleted": {
+			isCalendarUpdated: false,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test cases
+			fakeCli := fake.NewFakeClientWithScheme(s)
+			if c.calendar != nil {
+				require.NoError(t, fakeCli.Create(context.Background(), c.calendar))
+			}
+
+			// mocking cron manager
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockCronManager := cronFake.NewMockCronManager(ctrl)
+			calendarUpdated := false
+			if c.isCalendarUpdated {
+				mockCronManager.EXPECT().UpdateCalendar("test-ns", "test-calendar").DoAndReturn(func(string, string) error {
+					calendarUpdated = true
+					return nil
+				})
+			}
+
+			testController := &ScheduleCalendarReconciler{
+				Client:      fakeCli,
+				Log:         &test.FakeLogger{},
+				Scheme:      s,
+				CronManager: mockCronManager,
+			}
+
+			req := cRuntime.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: "test-ns",
+					Name:      "test-calendar",
+				},
+			}
+
+			// do testing function
+			_, err := testController.Reconcile(req)
+
+			// verify
+			require.NoError(t, err)
+			require.Equal(t, c.isCalendarUpdated, calendarUpdated)
+		})
+	}
+}
@@ -18,35 +18,61 @@ package controllers
 
 import (
 	"context"
+	goerrors "errors"
 	"fmt"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/k8s"
 	"github.com/tmax-cloud/scheduled-scaler-operator/internal/util"
 	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/apimanager"
-	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/cache"
 	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/cron"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/scaler"
 )
 
 const finalizer = "finalizer.scheduledscaler.tmax.io"
 
+// Reasons of events emitted by the reconciler
+const (
+	reasonInvalidSpec          = "InvalidSpec"
+	reasonTimeZoneLoadFailed   = "TimeZoneLoadFailed"
+	reasonCronUpdateFailed     = "CronUpdateFailed"
+	reasonCronUpdated          = "CronUpdated"
+	reasonDeletionPolicyFailed = "DeletionPolicyFailed"
+)
+
 // ScheduledScalerReconciler reconciles a ScheduledScaler object
 type ScheduledScalerReconciler struct {
 	client.Client
 	Log         logr.Logger
 	Scheme      *runtime.Scheme
-	cache       cache.ScheduledScalerCache
-	cronManager cron.CronManager
+	ScaleClient k8s.ScaleClient
+	Recorder    record.EventRecorder
+	// MaxConcurrentReconciles is the number of ScheduledScalers reconciled at once. It's 1 when it's not set
+	MaxConcurrentReconciles int
+	cronManager             cron.CronManager
 }
 
 // +kubebuilder:rbac:groups=tmax.io,resources=scheduledscalers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=tmax.io,resources=scheduledscalers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=tmax.io,resources=scheduledscalers/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=statefulsets;replicasets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=replicationcontrollers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=*,resources=*/scale,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
 func (r *ScheduledScalerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -64,19 +90,6 @@ func (r *ScheduledScalerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, er
 		return ctrl.Result{}, err
 	}
 
-	// set deleting flag to default: false
-	isDeleting := false
-	// deferring to manage cache
-	defer func() {
-		if isDeleting {
-			r.cache.Remove(scheduledScaler)
-			return
-		} else if apimanager.GetNamespacedName(*scheduledScaler) == "" {
-			return
-		}
-		r.cache.Put(scheduledScaler)
-	}()
-
 	// handle finalizer to check deleting event
 	if scheduledScaler.ObjectMeta.DeletionTimestamp.IsZero() {
 		if !util.ContainsString(scheduledScaler.ObjectMeta.Finalizers, finalizer) {
@@ -88,106 +101,186 @@ func (r *ScheduledScalerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, er
 		}
 	} else {
 		if util.ContainsString(scheduledScaler.ObjectMeta.Finalizers, finalizer) {
-			isDeleting = true // set deleting flag to remove scsc from cache
 			log.Info("deleting CR")
-			r.cronManager.RemoveCron(scheduledScaler) // remove cron of scsc
+			// adopted HPA is restored with removing cron, so the finalizer is kept until it's done
+			if err := r.cronManager.RemoveCron(scheduledScaler); err != nil {
+				log.Error(err, "Couldn't remove cron")
+				return ctrl.Result{}, err
+			}
+			if err := scaler.ApplyDeletionPolicy(r.Client, r.ScaleClient, *scheduledScaler); err != nil {
+				log.Error(err, "Couldn't apply deletion policy")
+				r.Recorder.Eventf(scheduledScaler, corev1.EventTypeWarning, reasonDeletionPolicyFailed, "Applying deletion policy failed: %v", err)
+				return ctrl.Result{}, err
+			}
 			scheduledScaler.ObjectMeta.Finalizers = util.RemoveString(scheduledScaler.ObjectMeta.Finalizers, finalizer)
 			if err := r.Update(ctx, scheduledScaler); err != nil {
 				return ctrl.Result{}, err
 			}
-
-			return ctrl.Result{}, nil
 		}
+
+		return ctrl.Result{}, nil
 	}
 
-	// When reconciled scsc is failed status and has reason InvalidSpecError, validate it again to check if it is modified
-	if scheduledScaler.Status.Phase == scscv1.StatusFailed && scheduledScaler.Status.Reason == scscv1.ValidationFailedError {
-		if !apimanager.Validate(scheduledScaler) {
+	// The generation is reconciled already, e.g. the event is caused by the status update or the managed HPA.
+	// Crons are rebuilt by the cron manager when the operator becomes the leader
+	if isReconciled(scheduledScaler) {
+		if scheduledScaler.Status.Phase != scscv1.StatusRunning {
 			return ctrl.Result{}, nil
 		}
+		// HPAs edited or deleted by hand are repaired
+		if err := r.cronManager.SyncCron(scheduledScaler); err != nil {
+			log.Error(err, "Couldn't repair managed HPAs")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
 	}
 
-	// When scsc has no status(creating) or failed status, update status to updating status to reconcile again
-	if scheduledScaler.Status.Phase == "" || scheduledScaler.Status.Phase == scscv1.StatusFailed {
-		if err := apimanager.UpdateStatus(
-			r.Client,
-			scheduledScaler,
-			scscv1.ScheduledScalerStatus{
-				Phase:   scscv1.StatusUpdating,
-				Message: "Scheduled Scaler is running",
-				Reason:  scscv1.NeedToReconcile,
-			}); err != nil {
+	// validate scsc and update cron
+	if errs := apimanager.Validate(scheduledScaler); len(errs) > 0 {
+		message := fmt.Sprintf("Scheduled Scaler spec is invalid: %v", errs.ToAggregate())
+		r.cronManager.RemoveCron(scheduledScaler)
+		r.Recorder.Event(scheduledScaler, corev1.EventTypeWarning, reasonInvalidSpec, message)
+		specValid := scscv1.Condition{
+			Type:               scscv1.ConditionSpecValid,
+			Status:             scscv1.ConditionFalse,
+			ObservedGeneration: scheduledScaler.Generation,
+			Reason:             scscv1.ReasonValidationFailed,
+			Message:            message,
+		}
+		if err := apimanager.UpdateStatus(r.Client, scheduledScaler, scscv1.ScheduledScalerStatus{
+			Phase:              scscv1.StatusFailed,
+			Message:            message,
+			Reason:             scscv1.ValidationFailedError,
+			ObservedGeneration: scheduledScaler.Generation,
+			Conditions:         []scscv1.Condition{specValid, apimanager.ReadyCondition(scheduledScaler.Generation, specValid)},
+		}); err != nil {
 			log.Error(err, "Updating status failed")
-			return ctrl.Result{}, nil
+			return ctrl.Result{}, err
 		}
+		log.Error(errs.ToAggregate(), "Invalid Spec is entered")
 		return ctrl.Result{}, nil
 	}
 
-	// When scsc has updating status, do reconciling logic: validate scsc and update cron
-	if scheduledScaler.Status.Phase == scscv1.StatusUpdating {
-		if !apimanager.Validate(scheduledScaler) {
-			r.cronManager.RemoveCron(scheduledScaler)
-			if err := apimanager.UpdateStatus(r.Client, scheduledScaler, scscv1.ScheduledScalerStatus{
-				Phase:   scscv1.StatusFailed,
-				Message: "Scheduled Scaler spec is invalid",
-				Reason:  scscv1.ValidationFailedError,
-			}); err != nil {
-				log.Error(err, "Updating status failed")
-				return ctrl.Result{}, nil
-			}
-			log.Error(fmt.Errorf("Invalid Spec is entered"), "Invalid Spec is entered")
-			return ctrl.Result{}, nil
-		}
+	// record replicas of targets before scaling, to restore them after deleting scsc.
+	// Crons aren't built until they're recorded, so that the targets are never scaled without them
+	if err := apimanager.RecordOriginalReplicas(r.Client, r.ScaleClient, scheduledScaler); err != nil {
+		log.Error(err, "Couldn't record original replicas")
+		return ctrl.Result{}, err
+	}
 
-		if err := r.cronManager.UpdateCron(scheduledScaler); err != nil {
-			log.Error(err, "Couldn't update cron")
-			if err = apimanager.UpdateStatus(r.Client, scheduledScaler, scscv1.ScheduledScalerStatus{
-				Phase:   scscv1.StatusFailed,
-				Message: "Scheduled Scaler is failed",
-				Reason:  scscv1.InternalLogicError,
-			}); err != nil {
-				log.Error(err, "Updating status failed")
-				return ctrl.Result{}, nil
-			}
-			return ctrl.Result{}, err
+	if err := r.cronManager.UpdateCron(scheduledScaler); err != nil {
+		if goerrors.Is(err, cron.ErrStandby) {
+			// the cron manager is starting, so the status is left until the cron is built
+			log.Info("Cron manager is in standby, the request is requeued")
+			return ctrl.Result{Requeue: true}, nil
 		}
-
-		log.Info("Reconciling done")
-		if err := apimanager.UpdateStatus(r.Client, scheduledScaler, scscv1.ScheduledScalerStatus{
-			Phase:   scscv1.StatusRunning,
-			Message: "Scheduled Scaler is running",
-			Reason:  scscv1.ReconcileDone,
-		}); err != nil {
-			log.Error(err, "Updating status failed")
-			return ctrl.Result{}, nil
+		log.Error(err, "Couldn't update cron")
+		var tzErr *cron.TimeZoneError
+		if goerrors.As(err, &tzErr) {
+			r.Recorder.Event(scheduledScaler, corev1.EventTypeWarning, reasonTimeZoneLoadFailed, err.Error())
+		} else {
+			r.Recorder.Eventf(scheduledScaler, corev1.EventTypeWarning, reasonCronUpdateFailed, "Updating cron failed: %v", err)
 		}
-	} else {
-		// In else case (Running status), check if scsc is modified. If it's modified, update status to Updating to reconcile again
-		if r.cache.HasChanged(scheduledScaler) {
-			if err := apimanager.UpdateStatus(r.Client, scheduledScaler, scscv1.ScheduledScalerStatus{
-				Phase:   scscv1.StatusUpdating,
-				Message: "Scheduled Scaler is running",
-				Reason:  scscv1.NeedToReconcile,
-			}); err != nil {
-				log.Error(err, "Updating status failed")
-				return ctrl.Result{}, nil
-			}
-			return ctrl.Result{}, nil
+		// observed generation isn't updated, so that updating cron is retried
+		if statusErr := apimanager.UpdateStatus(r.Client, scheduledScaler, scscv1.ScheduledScalerStatus{
+			Phase:   scscv1.StatusFailed,
+			Message: "Scheduled Scaler is failed",
+			Reason:  scscv1.InternalLogicError,
+			Conditions: []scscv1.Condition{{
+				Type:               scscv1.ConditionReady,
+				Status:             scscv1.ConditionFalse,
+				ObservedGeneration: scheduledScaler.Generation,
+				Reason:             scscv1.ReasonInternalError,
+				Message:            err.Error(),
+			}},
+		}); statusErr != nil {
+			log.Error(statusErr, "Updating status failed")
 		}
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Reconciling done")
+	r.Recorder.Event(scheduledScaler, corev1.EventTypeNormal, reasonCronUpdated, "Crons of schedules are updated")
+	targetFound := apimanager.TargetCondition(r.Client, r.ScaleClient, scheduledScaler)
+	if targetFound.Status != scscv1.ConditionTrue {
+		r.Recorder.Event(scheduledScaler, corev1.EventTypeWarning, scaler.ReasonTargetNotFound, targetFound.Message)
+	}
+	conditions := []scscv1.Condition{
+		{
+			Type:               scscv1.ConditionSpecValid,
+			Status:             scscv1.ConditionTrue,
+			ObservedGeneration: scheduledScaler.Generation,
+			Reason:             scscv1.ReasonValidationPassed,
+			Message:            "Scheduled Scaler spec is valid",
+		},
+		targetFound,
+		apimanager.HpaCondition(r.Client, scheduledScaler),
+	}
+	conditions = append(conditions, apimanager.ReadyCondition(scheduledScaler.Generation, conditions...))
+	if err := apimanager.UpdateStatus(r.Client, scheduledScaler, scscv1.ScheduledScalerStatus{
+		Phase:              scscv1.StatusRunning,
+		Message:            "Scheduled Scaler is running",
+		Reason:             scscv1.ReconcileDone,
+		ObservedGeneration: scheduledScaler.Generation,
+		Conditions:         conditions,
+	}); err != nil {
+		log.Error(err, "Updating status failed")
+		return ctrl.Result{}, err
 	}
 
 	return ctrl.Result{}, nil
 }
 
-// Init is for initiating member components: cron manager and cache
+// managedHpaPredicate passes HPAs edited or deleted by others. HPAs are created by the operator,
+// and changes of their status by the HPA controller don't need reconciling
+var managedHpaPredicate = predicate.Funcs{
+	CreateFunc: func(event.CreateEvent) bool {
+		return false
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return k8s.HpaChanged(e.ObjectOld, e.ObjectNew)
+	},
+	DeleteFunc: func(event.DeleteEvent) bool {
+		return true
+	},
+	GenericFunc: func(event.GenericEvent) bool {
+		return false
+	},
+}
+
+// isReconciled returns whether the current generation of the scsc is running or failed validation.
+// Failures of updating cron don't update the observed generation, so they're retried
+func isReconciled(scsc *scscv1.ScheduledScaler) bool {
+	if scsc.Status.ObservedGeneration != scsc.Generation {
+		return false
+	}
+
+	switch scsc.Status.Phase {
+	case scscv1.StatusRunning:
+		return true
+	case scscv1.StatusFailed:
+		return scsc.Status.Reason == scscv1.ValidationFailedError
+	default:
+		return false
+	}
+}
+
+// Init is for initiating member components: cron manager
 func (r *ScheduledScalerReconciler) Init() *ScheduledScalerReconciler {
-	r.cronManager = cron.NewCronManager(r.Client)
-	r.cache = cache.New()
+	r.cronManager = cron.NewCronManager(r.Client, r.ScaleClient, r.Recorder)
 	return r
 }
 
+// CronManager returns the cron manager to share it with other reconcilers
+func (r *ScheduledScalerReconciler) CronManager() cron.CronManager {
+	return r.cronManager
+}
+
 func (r *ScheduledScalerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&scscv1.ScheduledScaler{}).
+		// HPAs are watched with the autoscaling API version which the server offers
+		Owns(k8s.NewHpaObject(), builder.WithPredicates(managedHpaPredicate)).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
@@ -3,18 +3,26 @@ package controllers
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
 	"github.com/tmax-cloud/scheduled-scaler-operator/internal/test"
-	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/cache"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/apimanager"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/cron"
 	cronFake "github.com/tmax-cloud/scheduled-scaler-operator/pkg/cron/fake"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/scaler"
+	autov2beta2 "k8s.io/api/autoscaling/v2beta2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
 	cRuntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -31,10 +39,19 @@ func TestScheduledScalerController_Reconcile(t *testing.T) {
 
 		expectedFinalizer []string
 		expectedStatus    scscv1.ScheduledScalerStatus
-		isCronUpdated     bool
-		isCronRemoved     bool
-		cronUpdateFailed  bool
-		inCache           *scscv1.ScheduledScaler
+		// statuses of conditions by type. Conditions are verified apart from status because of transition times
+		expectedConditions map[string]scscv1.ConditionStatus
+		isCronUpdated      bool
+		isCronRemoved      bool
+		isCronSynced       bool
+		cronUpdateFailed   bool
+		cronRemoveFailed   bool
+		targetExists       bool
+		timeZoneFailed     bool
+		scaleFailed        bool
+		standby            bool
+		// reasons of emitted events
+		expectedEvents []string
 	}{
 		"scheduled scaler first created": {
 			scsc: &scscv1.ScheduledScaler{
@@ -55,15 +72,70 @@ func TestScheduledScalerController_Reconcile(t *testing.T) {
 					},
 				},
 			},
+			expectedEvents:    []string{reasonCronUpdated, scaler.ReasonTargetNotFound},
 			expectedFinalizer: []string{finalizer},
 			expectedStatus: scscv1.ScheduledScalerStatus{
-				Phase:   scscv1.StatusUpdating,
+				Phase:   scscv1.StatusRunning,
 				Message: "Scheduled Scaler is running",
-				Reason:  scscv1.NeedToReconcile,
+				Reason:  scscv1.ReconcileDone,
 			},
-			isCronUpdated: false,
+			expectedConditions: map[string]scscv1.ConditionStatus{
+				scscv1.ConditionReady:       scscv1.ConditionFalse,
+				scscv1.ConditionSpecValid:   scscv1.ConditionTrue,
+				scscv1.ConditionTargetFound: scscv1.ConditionFalse,
+				scscv1.ConditionHPAReady:    scscv1.ConditionTrue,
+			},
+			isCronUpdated: true,
 			isCronRemoved: false,
 		},
+		"cron manager in standby": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Replicas: &scaledReplica,
+						},
+					},
+				},
+			},
+			expectedFinalizer: []string{finalizer},
+			isCronUpdated:     false,
+			isCronRemoved:     false,
+			standby:           true,
+		},
+		"recording original replicas failed": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Replicas: &scaledReplica,
+						},
+					},
+				},
+			},
+			expectedFinalizer: []string{finalizer},
+			isCronUpdated:     false,
+			isCronRemoved:     false,
+			scaleFailed:       true,
+		},
 		"scheduled scaler in updating status and done well": {
 			scsc: &scscv1.ScheduledScaler{
 				ObjectMeta: metav1.ObjectMeta{
@@ -89,15 +161,67 @@ func TestScheduledScalerController_Reconcile(t *testing.T) {
 					Reason:  scscv1.NeedToReconcile,
 				},
 			},
+			expectedEvents:    []string{reasonCronUpdated, scaler.ReasonTargetNotFound},
 			expectedFinalizer: []string{finalizer},
 			expectedStatus: scscv1.ScheduledScalerStatus{
 				Phase:   scscv1.StatusRunning,
 				Message: "Scheduled Scaler is running",
 				Reason:  scscv1.ReconcileDone,
 			},
+			expectedConditions: map[string]scscv1.ConditionStatus{
+				scscv1.ConditionReady:       scscv1.ConditionFalse,
+				scscv1.ConditionSpecValid:   scscv1.ConditionTrue,
+				scscv1.ConditionTargetFound: scscv1.ConditionFalse,
+				scscv1.ConditionHPAReady:    scscv1.ConditionTrue,
+			},
 			isCronUpdated: true,
 			isCronRemoved: false,
 		},
+		"scheduled scaler in updating status and target found": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-scsc",
+					Namespace:  "test-ns",
+					Finalizers: []string{finalizer},
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Replicas: &scaledReplica,
+						},
+					},
+				},
+				Status: scscv1.ScheduledScalerStatus{
+					Phase:   scscv1.StatusUpdating,
+					Message: "Scheduled Scaler is running",
+					Reason:  scscv1.NeedToReconcile,
+				},
+			},
+			expectedEvents:    []string{reasonCronUpdated},
+			expectedFinalizer: []string{finalizer},
+			expectedStatus: scscv1.ScheduledScalerStatus{
+				Phase:   scscv1.StatusRunning,
+				Message: "Scheduled Scaler is running",
+				Reason:  scscv1.ReconcileDone,
+				OriginalReplicas: []scscv1.TargetReplicas{
+					{Name: "test-deploy", Replicas: 1},
+				},
+			},
+			expectedConditions: map[string]scscv1.ConditionStatus{
+				scscv1.ConditionReady:       scscv1.ConditionTrue,
+				scscv1.ConditionSpecValid:   scscv1.ConditionTrue,
+				scscv1.ConditionTargetFound: scscv1.ConditionTrue,
+				scscv1.ConditionHPAReady:    scscv1.ConditionTrue,
+			},
+			isCronUpdated: true,
+			targetExists:  true,
+			isCronRemoved: false,
+		},
 		"scheduled scaler in updating status and validation failed": {
 			scsc: &scscv1.ScheduledScaler{
 				ObjectMeta: metav1.ObjectMeta{
@@ -123,12 +247,17 @@ func TestScheduledScalerController_Reconcile(t *testing.T) {
 					Reason:  scscv1.NeedToReconcile,
 				},
 			},
+			expectedEvents:    []string{reasonInvalidSpec},
 			expectedFinalizer: []string{finalizer},
 			expectedStatus: scscv1.ScheduledScalerStatus{
 				Phase:   scscv1.StatusFailed,
-				Message: "Scheduled Scaler spec is invalid",
+				Message: "Scheduled Scaler spec is invalid: spec.schedule[0].replicas: Required value: must be set for fixed schedule",
 				Reason:  scscv1.ValidationFailedError,
 			},
+			expectedConditions: map[string]scscv1.ConditionStatus{
+				scscv1.ConditionReady:     scscv1.ConditionFalse,
+				scscv1.ConditionSpecValid: scscv1.ConditionFalse,
+			},
 			isCronUpdated: false,
 			isCronRemoved: true,
 		},
@@ -157,17 +286,21 @@ func TestScheduledScalerController_Reconcile(t *testing.T) {
 					Reason:  scscv1.NeedToReconcile,
 				},
 			},
+			expectedEvents:    []string{reasonCronUpdateFailed},
 			expectedFinalizer: []string{finalizer},
 			expectedStatus: scscv1.ScheduledScalerStatus{
 				Phase:   scscv1.StatusFailed,
 				Message: "Scheduled Scaler is failed",
 				Reason:  scscv1.InternalLogicError,
 			},
+			expectedConditions: map[string]scscv1.ConditionStatus{
+				scscv1.ConditionReady: scscv1.ConditionFalse,
+			},
 			isCronUpdated:    false,
 			isCronRemoved:    false,
 			cronUpdateFailed: true,
 		},
-		"scheduled scaler in failed status": {
+		"scheduled scaler in updating status and cron updating failed by time zone": {
 			scsc: &scscv1.ScheduledScaler{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:       "test-scsc",
@@ -187,28 +320,32 @@ func TestScheduledScalerController_Reconcile(t *testing.T) {
 					},
 				},
 				Status: scscv1.ScheduledScalerStatus{
-					Phase:   scscv1.StatusFailed,
-					Message: "Scheduled Scaler is failed",
-					Reason:  scscv1.InternalLogicError,
+					Phase:   scscv1.StatusUpdating,
+					Message: "Scheduled Scaler is running",
+					Reason:  scscv1.NeedToReconcile,
 				},
 			},
+			expectedEvents:    []string{reasonTimeZoneLoadFailed},
 			expectedFinalizer: []string{finalizer},
 			expectedStatus: scscv1.ScheduledScalerStatus{
-				Phase:   scscv1.StatusUpdating,
-				Message: "Scheduled Scaler is running",
-				Reason:  scscv1.NeedToReconcile,
+				Phase:   scscv1.StatusFailed,
+				Message: "Scheduled Scaler is failed",
+				Reason:  scscv1.InternalLogicError,
+			},
+			expectedConditions: map[string]scscv1.ConditionStatus{
+				scscv1.ConditionReady: scscv1.ConditionFalse,
 			},
 			isCronUpdated:    false,
 			isCronRemoved:    false,
-			cronUpdateFailed: false,
+			cronUpdateFailed: true,
+			timeZoneFailed:   true,
 		},
-		"scheduled scaler in running status when not changed": {
+		"scheduled scaler in failed status": {
 			scsc: &scscv1.ScheduledScaler{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:       "test-scsc",
 					Namespace:  "test-ns",
 					Finalizers: []string{finalizer},
-					Generation: 1,
 				},
 				Spec: scscv1.ScheduledScalerSpec{
 					Target: scscv1.SchedulingTarget{
@@ -223,21 +360,30 @@ func TestScheduledScalerController_Reconcile(t *testing.T) {
 					},
 				},
 				Status: scscv1.ScheduledScalerStatus{
-					Phase:   scscv1.StatusRunning,
-					Message: "Scheduled Scaler is running",
-					Reason:  scscv1.ReconcileDone,
+					Phase:   scscv1.StatusFailed,
+					Message: "Scheduled Scaler is failed",
+					Reason:  scscv1.InternalLogicError,
 				},
 			},
+			expectedEvents:    []string{reasonCronUpdated, scaler.ReasonTargetNotFound},
 			expectedFinalizer: []string{finalizer},
 			expectedStatus: scscv1.ScheduledScalerStatus{
 				Phase:   scscv1.StatusRunning,
 				Message: "Scheduled Scaler is running",
 				Reason:  scscv1.ReconcileDone,
 			},
-			isCronUpdated:    false,
+			expectedConditions: map[string]scscv1.ConditionStatus{
+				scscv1.ConditionReady:       scscv1.ConditionFalse,
+				scscv1.ConditionSpecValid:   scscv1.ConditionTrue,
+				scscv1.ConditionTargetFound: scscv1.ConditionFalse,
+				scscv1.ConditionHPAReady:    scscv1.ConditionTrue,
+			},
+			isCronUpdated:    true,
 			isCronRemoved:    false,
 			cronUpdateFailed: false,
-			inCache: &scscv1.ScheduledScaler{
+		},
+		"scheduled scaler in failed status by invalid spec when not changed": {
+			scsc: &scscv1.ScheduledScaler{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:       "test-scsc",
 					Namespace:  "test-ns",
@@ -250,20 +396,30 @@ func TestScheduledScalerController_Reconcile(t *testing.T) {
 					},
 					Schedule: []scscv1.Schedule{
 						{
-							Type:     "fixed",
-							Runat:    "* * * * *",
-							Replicas: &scaledReplica,
+							Type:  "fixed",
+							Runat: "* * * * *",
 						},
 					},
 				},
 				Status: scscv1.ScheduledScalerStatus{
-					Phase:   scscv1.StatusRunning,
-					Message: "Scheduled Scaler is running",
-					Reason:  scscv1.ReconcileDone,
+					Phase:              scscv1.StatusFailed,
+					Message:            "Scheduled Scaler spec is invalid",
+					Reason:             scscv1.ValidationFailedError,
+					ObservedGeneration: 1,
 				},
 			},
+			expectedFinalizer: []string{finalizer},
+			expectedStatus: scscv1.ScheduledScalerStatus{
+				Phase:              scscv1.StatusFailed,
+				Message:            "Scheduled Scaler spec is invalid",
+				Reason:             scscv1.ValidationFailedError,
+				ObservedGeneration: 1,
+			},
+			isCronUpdated:    false,
+			isCronRemoved:    false,
+			cronUpdateFailed: false,
 		},
-		"scheduled scaler in running status when changed": {
+		"scheduled scaler in running status when not changed": {
 			scsc: &scscv1.ScheduledScaler{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:       "test-scsc",
@@ -284,21 +440,26 @@ func TestScheduledScalerController_Reconcile(t *testing.T) {
 					},
 				},
 				Status: scscv1.ScheduledScalerStatus{
-					Phase:   scscv1.StatusRunning,
-					Message: "Scheduled Scaler is running",
-					Reason:  scscv1.ReconcileDone,
+					Phase:              scscv1.StatusRunning,
+					Message:            "Scheduled Scaler is running",
+					Reason:             scscv1.ReconcileDone,
+					ObservedGeneration: 1,
 				},
 			},
 			expectedFinalizer: []string{finalizer},
 			expectedStatus: scscv1.ScheduledScalerStatus{
-				Phase:   scscv1.StatusUpdating,
-				Message: "Scheduled Scaler is running",
-				Reason:  scscv1.NeedToReconcile,
+				Phase:              scscv1.StatusRunning,
+				Message:            "Scheduled Scaler is running",
+				Reason:             scscv1.ReconcileDone,
+				ObservedGeneration: 1,
 			},
 			isCronUpdated:    false,
 			isCronRemoved:    false,
+			isCronSynced:     true,
 			cronUpdateFailed: false,
-			inCache: &scscv1.ScheduledScaler{
+		},
+		"scheduled scaler in running status when changed": {
+			scsc: &scscv1.ScheduledScaler{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:       "test-scsc",
 					Namespace:  "test-ns",
@@ -318,11 +479,29 @@ func TestScheduledScalerController_Reconcile(t *testing.T) {
 					},
 				},
 				Status: scscv1.ScheduledScalerStatus{
-					Phase:   scscv1.StatusRunning,
-					Message: "Scheduled Scaler is running",
-					Reason:  scscv1.ReconcileDone,
+					Phase:              scscv1.StatusRunning,
+					Message:            "Scheduled Scaler is running",
+					Reason:             scscv1.ReconcileDone,
+					ObservedGeneration: 1,
 				},
 			},
+			expectedEvents:    []string{reasonCronUpdated, scaler.ReasonTargetNotFound},
+			expectedFinalizer: []string{finalizer},
+			expectedStatus: scscv1.ScheduledScalerStatus{
+				Phase:              scscv1.StatusRunning,
+				Message:            "Scheduled Scaler is running",
+				Reason:             scscv1.ReconcileDone,
+				ObservedGeneration: 2,
+			},
+			expectedConditions: map[string]scscv1.ConditionStatus{
+				scscv1.ConditionReady:       scscv1.ConditionFalse,
+				scscv1.ConditionSpecValid:   scscv1.ConditionTrue,
+				scscv1.ConditionTargetFound: scscv1.ConditionFalse,
+				scscv1.ConditionHPAReady:    scscv1.ConditionTrue,
+			},
+			isCronUpdated:    true,
+			isCronRemoved:    false,
+			cronUpdateFailed: false,
 		},
 		"scheduled scaler in deleting process": {
 			scsc: &scscv1.ScheduledScaler{
@@ -349,6 +528,32 @@ func TestScheduledScalerController_Reconcile(t *testing.T) {
 			isCronRemoved:    true,
 			cronUpdateFailed: false,
 		},
+		"restoring adopted HPA failed in deleting process": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-scsc",
+					Namespace:         "test-ns",
+					Finalizers:        []string{finalizer},
+					DeletionTimestamp: &nowTime,
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Replicas: &scaledReplica,
+						},
+					},
+				},
+			},
+			// the finalizer is kept, so that removing cron is retried
+			expectedFinalizer: []string{finalizer},
+			isCronRemoved:     true,
+			cronRemoveFailed:  true,
+		},
 	}
 
 	for name, c := range tc {
@@ -366,26 +571,52 @@ func TestScheduledScalerController_Reconcile(t *testing.T) {
 					cronUpdated = true
 					return nil
 				})
+			} else if c.standby {
+				mockCronManager.EXPECT().UpdateCron(gomock.Any()).Return(cron.ErrStandby)
 			} else if c.cronUpdateFailed {
 				mockCronManager.EXPECT().UpdateCron(gomock.Any()).DoAndReturn(func(*scscv1.ScheduledScaler) error {
+					if c.timeZoneFailed {
+						return &cron.TimeZoneError{TimeZone: "Invalid/Zone", Err: errors.New("unknown time zone")}
+					}
 					return errors.New("cron update fail")
 				})
 			}
 
+			cronSynced := false
+			if c.isCronSynced {
+				mockCronManager.EXPECT().SyncCron(gomock.Any()).DoAndReturn(func(*scscv1.ScheduledScaler) error {
+					cronSynced = true
+					return nil
+				})
+			}
+
 			cronRemoved := false
 			if c.isCronRemoved {
 				mockCronManager.EXPECT().RemoveCron(gomock.Any()).DoAndReturn(func(*scscv1.ScheduledScaler) error {
 					cronRemoved = true
+					if c.cronRemoveFailed {
+						return errors.New("restoring adopted hpa fail")
+					}
 					return nil
 				})
 			}
 
+			recorder := record.NewFakeRecorder(10)
+			fakeScale := test.NewFakeScaleClient()
+			if c.targetExists {
+				fakeScale.Set(c.scsc.Spec.Target, c.scsc.Namespace, 1)
+			}
+			if c.scaleFailed {
+				fakeScale.Err = errors.New("scale fail")
+			}
+
 			testController := &ScheduledScalerReconciler{
 				Client:      fakeCli,
 				Log:         &test.FakeLogger{},
 				Scheme:      s,
+				ScaleClient: fakeScale,
+				Recorder:    recorder,
 				cronManager: mockCronManager,
-				cache:       cache.New(),
 			}
 
 			req := cRuntime.Request{
@@ -395,22 +626,195 @@ func TestScheduledScalerController_Reconcile(t *testing.T) {
 				},
 			}
 			fakeCli.Create(context.Background(), c.scsc)
-			if c.inCache != nil {
-				testController.cache.Put(c.inCache)
-			}
 
 			// do testing function
-			_, err := testController.Reconcile(req)
+			res, err := testController.Reconcile(req)
 			result := &scscv1.ScheduledScaler{}
 			gettingErr := fakeCli.Get(context.Background(), req.NamespacedName, result)
 
 			// verify
 			require.NoError(t, gettingErr)
-			require.NoError(t, err)
+			if c.cronUpdateFailed || c.scaleFailed || c.cronRemoveFailed {
+				// updating cron is retried with the returned error
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			// the request is requeued until the cron manager is started
+			require.Equal(t, c.standby, res.Requeue)
 			require.Equal(t, c.expectedFinalizer, result.ObjectMeta.Finalizers)
+			conditions := result.Status.Conditions
+			result.Status.Conditions = nil
 			require.Equal(t, c.expectedStatus, result.Status)
+			require.Len(t, conditions, len(c.expectedConditions))
+			for conditionType, status := range c.expectedConditions {
+				condition := apimanager.FindCondition(conditions, conditionType)
+				require.NotNil(t, condition, conditionType)
+				require.Equal(t, status, condition.Status, conditionType)
+			}
 			require.Equal(t, c.isCronRemoved, cronRemoved)
 			require.Equal(t, c.isCronUpdated, cronUpdated)
+			require.Equal(t, c.isCronSynced, cronSynced)
+			close(recorder.Events)
+			reasons := []string{}
+			for event := range recorder.Events {
+				reasons = append(reasons, strings.Fields(event)[1])
+			}
+			require.Equal(t, len(c.expectedEvents), len(reasons), reasons)
+			for i := range c.expectedEvents {
+				require.Equal(t, c.expectedEvents[i], reasons[i])
+			}
 		})
 	}
 }
+
+func TestScheduledScalerController_ConcurrentReconcile(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	// set test case
+	count := 20
+	replica := int32(1)
+	scaledReplica := int32(2)
+	fakeCli := fake.NewFakeClientWithScheme(s)
+	fakeScale := test.NewFakeScaleClient()
+	newScsc := func(name string) *scscv1.ScheduledScaler {
+		return &scscv1.ScheduledScaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test-ns",
+			},
+			Spec: scscv1.ScheduledScalerSpec{
+				Target: scscv1.SchedulingTarget{Name: name + "-deploy"},
+				Schedule: []scscv1.Schedule{
+					{
+						Type:     "fixed",
+						Runat:    "0 0 0 1 1 *",
+						Replicas: &scaledReplica,
+					},
+				},
+			},
+		}
+	}
+	// the probe is running already, so that its missed schedule is caught up when the cron manager starts
+	probe := newScsc("probe")
+	probe.Status.Phase = scscv1.StatusRunning
+	require.NoError(t, fakeCli.Create(context.Background(), probe))
+	fakeScale.Set(probe.Spec.Target, probe.Namespace, replica)
+	names := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		scsc := newScsc(fmt.Sprintf("test-scsc-%d", i))
+		require.NoError(t, fakeCli.Create(context.Background(), scsc))
+		fakeScale.Set(scsc.Spec.Target, scsc.Namespace, replica)
+		names = append(names, scsc.Name)
+	}
+
+	testController := (&ScheduledScalerReconciler{
+		Client:                  fakeCli,
+		Log:                     &test.FakeLogger{},
+		Scheme:                  s,
+		ScaleClient:             fakeScale,
+		Recorder:                &record.FakeRecorder{},
+		MaxConcurrentReconciles: count,
+	}).Init()
+	stop := make(chan struct{})
+	defer close(stop)
+	go testController.CronManager().Start(stop)
+	require.Eventually(t, func() bool {
+		current, err := fakeScale.GetReplicas(probe.Spec.Target, probe.Namespace)
+		return err == nil && current == scaledReplica
+	}, time.Second, 10*time.Millisecond)
+
+	// do testing function
+	var wg sync.WaitGroup
+	errs := make(chan error, count*2)
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			req := cRuntime.Request{NamespacedName: types.NamespacedName{Namespace: "test-ns", Name: name}}
+			// the first reconcile builds its cron, and the second does nothing because the generation is reconciled
+			for i := 0; i < 2; i++ {
+				_, err := testController.Reconcile(req)
+				errs <- err
+			}
+		}(name)
+	}
+	wg.Wait()
+	close(errs)
+
+	// verify by cases
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	for _, name := range names {
+		result := &scscv1.ScheduledScaler{}
+		require.NoError(t, fakeCli.Get(context.Background(), types.NamespacedName{Namespace: "test-ns", Name: name}, result))
+		require.Equal(t, scscv1.StatusRunning, result.Status.Phase, name)
+		current, err := fakeScale.GetReplicas(result.Spec.Target, result.Namespace)
+		require.NoError(t, err)
+		require.Equal(t, scaledReplica, current, name)
+	}
+}
+
+func TestScheduledScalerController_ReconcileKeepsCronStatus(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+
+	// set test case
+	scaledReplica := int32(2)
+	lastRunTime := metav1.NewTime(time.Now().Truncate(time.Second))
+	scsc := &scscv1.ScheduledScaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-scsc",
+			Namespace:  "test-ns",
+			Finalizers: []string{finalizer},
+		},
+		Spec: scscv1.ScheduledScalerSpec{
+			Target: scscv1.SchedulingTarget{Name: "test-deploy"},
+			Schedule: []scscv1.Schedule{
+				{
+					Type:     "fixed",
+					Runat:    "0 0 0 1 1 *",
+					Replicas: &scaledReplica,
+				},
+			},
+		},
+	}
+	fakeCli := fake.NewFakeClientWithScheme(s)
+	require.NoError(t, fakeCli.Create(context.Background(), scsc))
+	req := cRuntime.Request{NamespacedName: types.NamespacedName{Namespace: scsc.Namespace, Name: scsc.Name}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockCronManager := cronFake.NewMockCronManager(ctrl)
+	// the missed schedule is caught up while the cron is built, after the reconciler read the scsc
+	mockCronManager.EXPECT().UpdateCron(gomock.Any()).DoAndReturn(func(*scscv1.ScheduledScaler) error {
+		return apimanager.UpdateScheduleStatus(fakeCli, req.NamespacedName, []scscv1.ScheduleStatus{
+			{Index: 0, LastRunTime: &lastRunTime, LastResult: scscv1.ScheduleResultSucceeded},
+		}, nil, nil)
+	})
+	testController := &ScheduledScalerReconciler{
+		Client:      fakeCli,
+		Log:         &test.FakeLogger{},
+		Scheme:      s,
+		ScaleClient: test.NewFakeScaleClient(),
+		Recorder:    record.NewFakeRecorder(10),
+		cronManager: mockCronManager,
+	}
+
+	// do testing function
+	_, err := testController.Reconcile(req)
+
+	// verify by cases
+	require.NoError(t, err)
+	result := &scscv1.ScheduledScaler{}
+	require.NoError(t, fakeCli.Get(context.Background(), req.NamespacedName, result))
+	require.Equal(t, scscv1.StatusRunning, result.Status.Phase)
+	require.Len(t, result.Status.Schedules, 1)
+	lastScale := apimanager.FindCondition(result.Status.Conditions, scscv1.ConditionLastScaleSucceeded)
+	require.NotNil(t, lastScale)
+	require.Equal(t, scscv1.ConditionTrue, lastScale.Status)
+	require.NotNil(t, apimanager.FindCondition(result.Status.Conditions, scscv1.ConditionReady))
+}
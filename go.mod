@@ -5,10 +5,12 @@ go 1.13
 require (
 	github.com/go-logr/logr v0.1.0
 	github.com/golang/mock v1.2.0
+	github.com/prometheus/client_golang v1.0.0
 	github.com/robfig/cron v1.2.0
 	github.com/stretchr/testify v1.4.0
 	k8s.io/api v0.18.6
 	k8s.io/apimachinery v0.18.6
 	k8s.io/client-go v0.18.6
+	k8s.io/utils v0.0.0-20200603063816-c1c6865ac451
 	sigs.k8s.io/controller-runtime v0.6.2
 )
@@ -0,0 +1,95 @@
+package k8s
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/util"
+	autov2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Annotations on HPA adopted by scheduled scaler. Original bounds are recorded to be restored later
+const (
+	AdoptedByAnnotation           = "scheduledscaler.tmax.io/adopted-by"
+	OriginalMinReplicasAnnotation = "scheduledscaler.tmax.io/original-min-replicas"
+	OriginalMaxReplicasAnnotation = "scheduledscaler.tmax.io/original-max-replicas"
+)
+
+// PatchAdoptedHpaReplicas patches only minReplicas/maxReplicas of the existing HPA.
+// Original bounds are recorded in annotations when the HPA is adopted at first
+func PatchAdoptedHpaReplicas(cl client.Client, scheduledScalerName, name, namespace string, minReplicas *int32, maxReplicas int32) error {
+	hpa, err := GetHpa(cl, name, namespace)
+	if err != nil {
+		return fmt.Errorf("Getting adopted HPA failed: %v", err)
+	} else if hpa == nil {
+		return fmt.Errorf("Adopted HPA %s doesn't exist", name)
+	}
+
+	newHpa := hpa.DeepCopy()
+	if newHpa.Annotations == nil {
+		newHpa.Annotations = make(map[string]string)
+	}
+	if _, adopted := newHpa.Annotations[AdoptedByAnnotation]; !adopted {
+		newHpa.Annotations[AdoptedByAnnotation] = scheduledScalerName
+		newHpa.Annotations[OriginalMaxReplicasAnnotation] = strconv.Itoa(int(hpa.Spec.MaxReplicas))
+		newHpa.Annotations[OriginalMinReplicasAnnotation] = ""
+		if hpa.Spec.MinReplicas != nil {
+			newHpa.Annotations[OriginalMinReplicasAnnotation] = strconv.Itoa(int(*hpa.Spec.MinReplicas))
+		}
+	}
+	newHpa.Spec.MinReplicas = minReplicas
+	newHpa.Spec.MaxReplicas = maxReplicas
+	if err := patchHpa(cl, hpa, newHpa); err != nil {
+		return fmt.Errorf("Patch adopted Hpa failed: %v", err)
+	}
+
+	return nil
+}
+
+// RestoreAdoptedHpas restores original bounds of every HPA adopted by the scheduled scaler, except HPAs in the exceptions
+func RestoreAdoptedHpas(cl client.Client, scheduledScalerName, namespace string, exceptions ...string) error {
+	hpas, err := listHpas(cl, client.InNamespace(namespace))
+	if err != nil {
+		return fmt.Errorf("Listing Hpa failed in RestoreAdoptedHpas: %v", err)
+	}
+
+	for i := range hpas {
+		hpa := &hpas[i]
+		if hpa.Annotations[AdoptedByAnnotation] != scheduledScalerName || util.ContainsString(exceptions, hpa.Name) {
+			continue
+		}
+		if err := restoreHpaReplicas(cl, hpa); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func restoreHpaReplicas(cl client.Client, hpa *autov2beta2.HorizontalPodAutoscaler) error {
+	newHpa := hpa.DeepCopy()
+	maxReplicas, err := strconv.Atoi(hpa.Annotations[OriginalMaxReplicasAnnotation])
+	if err != nil {
+		return fmt.Errorf("Invalid original maxReplicas of HPA %s: %v", hpa.Name, err)
+	}
+	newHpa.Spec.MaxReplicas = int32(maxReplicas)
+	newHpa.Spec.MinReplicas = nil
+	if original := hpa.Annotations[OriginalMinReplicasAnnotation]; original != "" {
+		minReplicas, err := strconv.Atoi(original)
+		if err != nil {
+			return fmt.Errorf("Invalid original minReplicas of HPA %s: %v", hpa.Name, err)
+		}
+		min := int32(minReplicas)
+		newHpa.Spec.MinReplicas = &min
+	}
+	delete(newHpa.Annotations, AdoptedByAnnotation)
+	delete(newHpa.Annotations, OriginalMinReplicasAnnotation)
+	delete(newHpa.Annotations, OriginalMaxReplicasAnnotation)
+
+	if err := patchHpa(cl, hpa, newHpa); err != nil {
+		return fmt.Errorf("Restoring Hpa failed: %v", err)
+	}
+
+	return nil
+}
@@ -1,28 +1,38 @@
 package k8s
 
 import (
-	"context"
 	"fmt"
 
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/util"
 	autov2beta2 "k8s.io/api/autoscaling/v2beta2"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type HpaValidationOptions struct {
+	// Name of HPA. GetHpaName(ScheduledScalerName) is used when it's empty
+	Name                string
 	Namespace           string
-	Target              string
+	Target              scscv1.SchedulingTarget
 	ScheduledScalerName string
 	MinReplicas         *int32
 	MaxReplicas         *int32
+	// Metrics of HPA. 50% of CPU utilization is used when it's empty
+	Metrics []autov2beta2.MetricSpec
+	// Behavior of HPA. Cluster defaults are used when it's empty
+	Behavior *autov2beta2.HorizontalPodAutoscalerBehavior
+	// Owner is set as the controller of HPA, so that HPA is garbage collected with its owner
+	Owner *metav1.OwnerReference
 }
 
 func (o *HpaValidationOptions) validate() bool {
 	if o.Namespace == "" ||
-		o.Target == "" ||
+		o.Target.Name == "" ||
 		o.ScheduledScalerName == "" ||
 		o.MinReplicas == nil ||
 		o.MaxReplicas == nil {
@@ -32,40 +42,150 @@ func (o *HpaValidationOptions) validate() bool {
 	return true
 }
 
+func (o *HpaValidationOptions) metrics() []autov2beta2.MetricSpec {
+	if len(o.Metrics) > 0 {
+		return o.Metrics
+	}
+
+	utilization := int32(50)
+	return []autov2beta2.MetricSpec{
+		{
+			Type: autov2beta2.ResourceMetricSourceType,
+			Resource: &autov2beta2.ResourceMetricSource{
+				Name: v1.ResourceCPU,
+				Target: autov2beta2.MetricTarget{
+					Type:               autov2beta2.UtilizationMetricType,
+					AverageUtilization: &utilization,
+				},
+			},
+		},
+	}
+}
+
+func (o *HpaValidationOptions) name() string {
+	if o.Name != "" {
+		return o.Name
+	}
+	return GetHpaName(o.ScheduledScalerName)
+}
+
+func (o *HpaValidationOptions) spec() autov2beta2.HorizontalPodAutoscalerSpec {
+	return autov2beta2.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef: autov2beta2.CrossVersionObjectReference{
+			APIVersion: GetTargetAPIVersion(o.Target),
+			Kind:       GetTargetKind(o.Target),
+			Name:       o.Target.Name,
+		},
+		MinReplicas: o.MinReplicas,
+		MaxReplicas: *o.MaxReplicas,
+		Metrics:     o.metrics(),
+		Behavior:    o.Behavior,
+	}
+}
+
+// drifted returns whether the HPA differs from the options. Behavior is compared with the defaults filled in by the server
+func (o *HpaValidationOptions) drifted(hpa *autov2beta2.HorizontalPodAutoscaler) bool {
+	spec := o.spec()
+	if !equality.Semantic.DeepEqual(hpa.Spec.ScaleTargetRef, spec.ScaleTargetRef) ||
+		!equality.Semantic.DeepEqual(hpa.Spec.MinReplicas, spec.MinReplicas) ||
+		hpa.Spec.MaxReplicas != spec.MaxReplicas ||
+		!equality.Semantic.DeepEqual(hpa.Spec.Metrics, spec.Metrics) ||
+		!equality.Semantic.DeepEqual(defaultBehavior(hpa.Spec.Behavior), defaultBehavior(spec.Behavior)) {
+		return true
+	}
+
+	if o.Owner == nil {
+		return false
+	}
+	ref := metav1.GetControllerOf(hpa)
+	return ref == nil || ref.UID != o.Owner.UID
+}
+
+// defaultBehavior returns a copy of the behavior whose unset rules and fields are filled in with the defaults of the API server.
+// It returns nil if the behavior isn't set, because the server leaves it unset
+func defaultBehavior(behavior *autov2beta2.HorizontalPodAutoscalerBehavior) *autov2beta2.HorizontalPodAutoscalerBehavior {
+	if behavior == nil {
+		return nil
+	}
+
+	maxPolicy := autov2beta2.MaxPolicySelect
+	scaleUpWindow := int32(0)
+	defaulted := behavior.DeepCopy()
+	defaulted.ScaleUp = defaultScalingRules(defaulted.ScaleUp, autov2beta2.HPAScalingRules{
+		StabilizationWindowSeconds: &scaleUpWindow,
+		SelectPolicy:               &maxPolicy,
+		Policies: []autov2beta2.HPAScalingPolicy{
+			{Type: autov2beta2.PodsScalingPolicy, Value: 4, PeriodSeconds: 15},
+			{Type: autov2beta2.PercentScalingPolicy, Value: 100, PeriodSeconds: 15},
+		},
+	})
+	// stabilization window of scale down is left to the flag of the HPA controller
+	defaulted.ScaleDown = defaultScalingRules(defaulted.ScaleDown, autov2beta2.HPAScalingRules{
+		SelectPolicy: &maxPolicy,
+		Policies: []autov2beta2.HPAScalingPolicy{
+			{Type: autov2beta2.PercentScalingPolicy, Value: 100, PeriodSeconds: 15},
+		},
+	})
+	return defaulted
+}
+
+// defaultScalingRules fills in unset fields of the rules with the defaults
+func defaultScalingRules(rules *autov2beta2.HPAScalingRules, defaults autov2beta2.HPAScalingRules) *autov2beta2.HPAScalingRules {
+	if rules == nil {
+		return &defaults
+	}
+
+	if rules.StabilizationWindowSeconds == nil {
+		rules.StabilizationWindowSeconds = defaults.StabilizationWindowSeconds
+	}
+	if rules.SelectPolicy == nil {
+		rules.SelectPolicy = defaults.SelectPolicy
+	}
+	if rules.Policies == nil {
+		rules.Policies = defaults.Policies
+	}
+	return rules
+}
+
 func GetHpaName(scheduledScalerName string) string {
 	return fmt.Sprintf("%s-hpa", scheduledScalerName)
 }
 
+// GetTargetHpaName returns HPA name for one of the workloads selected by label selector
+func GetTargetHpaName(scheduledScalerName, targetName string) string {
+	return fmt.Sprintf("%s-%s-hpa", scheduledScalerName, targetName)
+}
+
+// GetHpa gets the HPA with the autoscaling API version offered by the server, converted into v2beta2.
+// It returns nil if the HPA doesn't exist
 func GetHpa(cl client.Client, name, namespace string) (*autov2beta2.HorizontalPodAutoscaler, error) {
-	hpa := &autov2beta2.HorizontalPodAutoscaler{}
-	if err := cl.Get(context.Background(), types.NamespacedName{Name: name, Namespace: namespace}, hpa); err != nil {
-		if errors.IsNotFound(err) {
-			return nil, nil
-		} else {
-			return nil, fmt.Errorf("Getting HPA failed")
-		}
+	hpa, err := getHpa(cl, types.NamespacedName{Name: name, Namespace: namespace})
+	if err != nil {
+		return nil, fmt.Errorf("Getting HPA failed")
 	}
 	return hpa, nil
 }
 
-func UpdateHpa(cl client.Client, options *HpaValidationOptions) error {
+// UpdateHpa creates the HPA, or updates it when it exists. It returns true when the HPA is created
+func UpdateHpa(cl client.Client, options *HpaValidationOptions) (bool, error) {
 	if !options.validate() {
-		return fmt.Errorf("Required options validation failed in CreateHpa")
+		return false, fmt.Errorf("Required options validation failed in CreateHpa")
 	}
 
-	hpaName := GetHpaName(options.ScheduledScalerName)
+	hpaName := options.name()
 	hpa, err := GetHpa(cl, hpaName, options.Namespace)
 	if err != nil {
-		return fmt.Errorf("Getting HPA failed in UpdateHPA")
+		return false, fmt.Errorf("Getting HPA failed in UpdateHPA")
 	} else if hpa != nil {
 		newHpa := hpa.DeepCopy()
-		newHpa.Spec.MinReplicas = options.MinReplicas
-		newHpa.Spec.MaxReplicas = *options.MaxReplicas
-		if err = cl.Patch(context.TODO(), newHpa, client.MergeFrom(hpa)); err != nil {
-			return fmt.Errorf("Patch Hpa failed: %v", err)
+		// the whole spec is replaced, so that every field compared by drifted is repaired
+		newHpa.Spec = options.spec()
+		// HPAs created before owner references are taken over
+		setOwner(newHpa, options.Owner)
+		if err = patchHpa(cl, hpa, newHpa); err != nil {
+			return false, fmt.Errorf("Patch Hpa failed: %v", err)
 		}
 	} else {
-		utilization := int32(50)
 		newHpa := &autov2beta2.HorizontalPodAutoscaler{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      hpaName,
@@ -74,34 +194,72 @@ func UpdateHpa(cl client.Client, options *HpaValidationOptions) error {
 					"owner": options.ScheduledScalerName,
 				},
 			},
-			Spec: autov2beta2.HorizontalPodAutoscalerSpec{
-				ScaleTargetRef: autov2beta2.CrossVersionObjectReference{
-					APIVersion: "apps/v1",
-					Kind:       "Deployment",
-					Name:       options.Target,
-				},
-				MinReplicas: options.MinReplicas,
-				MaxReplicas: *options.MaxReplicas,
-				Metrics: []autov2beta2.MetricSpec{
-					{
-						Type: autov2beta2.ResourceMetricSourceType,
-						Resource: &autov2beta2.ResourceMetricSource{
-							Name: v1.ResourceCPU,
-							Target: autov2beta2.MetricTarget{
-								Type:               autov2beta2.UtilizationMetricType,
-								AverageUtilization: &utilization,
-							},
-						},
-					},
-				},
-			},
+			Spec: options.spec(),
 		}
-		if err := cl.Create(context.Background(), newHpa); err != nil {
-			return fmt.Errorf("Creating Hpa failed: %v", err)
+		setOwner(newHpa, options.Owner)
+		if err := createHpa(cl, newHpa); err != nil {
+			return false, fmt.Errorf("Creating Hpa failed: %v", err)
 		}
+		return true, nil
 	}
 
-	return nil
+	return false, nil
+}
+
+// SyncHpa repairs the HPA when it's deleted or drifted from the options, e.g. edited by hand.
+// It returns true when the HPA is repaired
+func SyncHpa(cl client.Client, options *HpaValidationOptions) (bool, error) {
+	if !options.validate() {
+		return false, fmt.Errorf("Required options validation failed in SyncHpa")
+	}
+
+	hpa, err := GetHpa(cl, options.name(), options.Namespace)
+	if err != nil {
+		return false, fmt.Errorf("Getting HPA failed in SyncHpa")
+	} else if hpa != nil && !options.drifted(hpa) {
+		return false, nil
+	}
+
+	if _, err := UpdateHpa(cl, options); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// HpaChanged returns whether spec or owner references differ between HPAs of the autoscaling API version.
+// Changes of status made by the HPA controller are ignored
+func HpaChanged(oldObj, newObj runtime.Object) bool {
+	oldHpa, err := fromHpaObject(oldObj)
+	if err != nil {
+		return false
+	}
+	newHpa, err := fromHpaObject(newObj)
+	if err != nil {
+		return false
+	}
+
+	return !equality.Semantic.DeepEqual(oldHpa.Spec, newHpa.Spec) ||
+		!equality.Semantic.DeepEqual(oldHpa.OwnerReferences, newHpa.OwnerReferences)
+}
+
+// setOwner sets the owner as the controller of the HPA. The previous controller and references to previous owners
+// of the same name are replaced, because HPA can have only one controller
+func setOwner(hpa *autov2beta2.HorizontalPodAutoscaler, owner *metav1.OwnerReference) {
+	if owner == nil {
+		return
+	}
+	if ref := metav1.GetControllerOf(hpa); ref != nil && ref.UID == owner.UID {
+		return
+	}
+
+	refs := []metav1.OwnerReference{}
+	for _, ref := range hpa.OwnerReferences {
+		isController := ref.Controller != nil && *ref.Controller
+		if !isController && (ref.Kind != owner.Kind || ref.Name != owner.Name) {
+			refs = append(refs, ref)
+		}
+	}
+	hpa.OwnerReferences = append(refs, *owner)
 }
 
 func DeleteHpa(cl client.Client, name, namespace string) error {
@@ -109,10 +267,45 @@ func DeleteHpa(cl client.Client, name, namespace string) error {
 	if err != nil {
 		return fmt.Errorf("Getting Hpa failed in DeleteHpa")
 	} else if hpa != nil {
-		if err = cl.Delete(context.Background(), hpa); err != nil {
+		if err = deleteHpa(cl, hpa); err != nil {
 			return fmt.Errorf("Delete Hpa failed by: %v", err)
 		}
 	}
 
 	return nil
 }
+
+// DeleteOwnedHpas deletes every HPA created by the scheduled scaler, except HPAs in the exceptions.
+// It returns names of deleted HPAs
+func DeleteOwnedHpas(cl client.Client, scheduledScalerName, namespace string, exceptions ...string) ([]string, error) {
+	hpas, err := listHpas(cl, client.InNamespace(namespace), client.MatchingLabels{"owner": scheduledScalerName})
+	if err != nil {
+		return nil, fmt.Errorf("Listing Hpa failed in DeleteOwnedHpas: %v", err)
+	}
+
+	names := []string{GetHpaName(scheduledScalerName)}
+	for _, hpa := range hpas {
+		if hpa.Name != GetHpaName(scheduledScalerName) {
+			names = append(names, hpa.Name)
+		}
+	}
+
+	deleted := []string{}
+	for _, name := range names {
+		if util.ContainsString(exceptions, name) {
+			continue
+		}
+		hpa, err := GetHpa(cl, name, namespace)
+		if err != nil {
+			return deleted, fmt.Errorf("Getting Hpa failed in DeleteOwnedHpas")
+		} else if hpa == nil {
+			continue
+		}
+		if err := deleteHpa(cl, hpa); err != nil {
+			return deleted, fmt.Errorf("Delete Hpa failed by: %v", err)
+		}
+		deleted = append(deleted, name)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,211 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	autov2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	fakeCli "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSyncHpa(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	min := int32(1)
+	max := int32(3)
+	editedMax := int32(10)
+	controller := true
+	owner := &metav1.OwnerReference{
+		APIVersion: scscv1.GroupVersion.String(),
+		Kind:       "ScheduledScaler",
+		Name:       "test-scsc",
+		UID:        types.UID("test-uid"),
+		Controller: &controller,
+	}
+	previousOwner := owner.DeepCopy()
+	previousOwner.UID = types.UID("previous-uid")
+	window, editedWindow, scaleUpWindow := int32(60), int32(120), int32(0)
+	maxPolicy := autov2beta2.MaxPolicySelect
+	behavior := &autov2beta2.HorizontalPodAutoscalerBehavior{
+		ScaleDown: &autov2beta2.HPAScalingRules{StabilizationWindowSeconds: &window},
+	}
+	// behavior filled in with the defaults by the API server
+	defaultedBehavior := &autov2beta2.HorizontalPodAutoscalerBehavior{
+		ScaleUp: &autov2beta2.HPAScalingRules{
+			StabilizationWindowSeconds: &scaleUpWindow,
+			SelectPolicy:               &maxPolicy,
+			Policies: []autov2beta2.HPAScalingPolicy{
+				{Type: autov2beta2.PodsScalingPolicy, Value: 4, PeriodSeconds: 15},
+				{Type: autov2beta2.PercentScalingPolicy, Value: 100, PeriodSeconds: 15},
+			},
+		},
+		ScaleDown: &autov2beta2.HPAScalingRules{
+			StabilizationWindowSeconds: &window,
+			SelectPolicy:               &maxPolicy,
+			Policies: []autov2beta2.HPAScalingPolicy{
+				{Type: autov2beta2.PercentScalingPolicy, Value: 100, PeriodSeconds: 15},
+			},
+		},
+	}
+	tc := map[string]struct {
+		behavior *autov2beta2.HorizontalPodAutoscalerBehavior
+		// edit is applied to the HPA created by the options. The HPA is deleted when it's nil
+		edit             func(*autov2beta2.HorizontalPodAutoscaler)
+		expectedRepaired bool
+	}{
+		"not drifted": {
+			edit:             func(*autov2beta2.HorizontalPodAutoscaler) {},
+			expectedRepaired: false,
+		},
+		"status changed": {
+			edit: func(hpa *autov2beta2.HorizontalPodAutoscaler) {
+				hpa.Status.CurrentReplicas = 2
+			},
+			expectedRepaired: false,
+		},
+		"edited by hand": {
+			edit: func(hpa *autov2beta2.HorizontalPodAutoscaler) {
+				hpa.Spec.MaxReplicas = editedMax
+			},
+			expectedRepaired: true,
+		},
+		"scale target edited by hand": {
+			edit: func(hpa *autov2beta2.HorizontalPodAutoscaler) {
+				hpa.Spec.ScaleTargetRef.Name = "other-deploy"
+			},
+			expectedRepaired: true,
+		},
+		"behavior defaulted by server": {
+			behavior: behavior,
+			edit: func(hpa *autov2beta2.HorizontalPodAutoscaler) {
+				hpa.Spec.Behavior = defaultedBehavior.DeepCopy()
+			},
+			expectedRepaired: false,
+		},
+		"behavior edited by hand": {
+			behavior: behavior,
+			edit: func(hpa *autov2beta2.HorizontalPodAutoscaler) {
+				hpa.Spec.Behavior = defaultedBehavior.DeepCopy()
+				hpa.Spec.Behavior.ScaleDown.StabilizationWindowSeconds = &editedWindow
+			},
+			expectedRepaired: true,
+		},
+		"behavior added by hand": {
+			edit: func(hpa *autov2beta2.HorizontalPodAutoscaler) {
+				hpa.Spec.Behavior = defaultedBehavior.DeepCopy()
+			},
+			expectedRepaired: true,
+		},
+		"owned by previous scheduled scaler": {
+			edit: func(hpa *autov2beta2.HorizontalPodAutoscaler) {
+				hpa.OwnerReferences = []metav1.OwnerReference{*previousOwner}
+			},
+			expectedRepaired: true,
+		},
+		"deleted by hand": {
+			expectedRepaired: true,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			cl := fakeCli.NewFakeClientWithScheme(s)
+			options := &HpaValidationOptions{
+				Namespace:           "test-ns",
+				Target:              scscv1.SchedulingTarget{Name: "test-deploy"},
+				ScheduledScalerName: "test-scsc",
+				MinReplicas:         &min,
+				MaxReplicas:         &max,
+				Behavior:            c.behavior,
+				Owner:               owner,
+			}
+			created, err := UpdateHpa(cl, options)
+			require.NoError(t, err)
+			require.True(t, created)
+			hpa := &autov2beta2.HorizontalPodAutoscaler{}
+			require.NoError(t, cl.Get(context.Background(), types.NamespacedName{Name: "test-scsc-hpa", Namespace: "test-ns"}, hpa))
+			require.Equal(t, []metav1.OwnerReference{*owner}, hpa.OwnerReferences)
+			if c.edit != nil {
+				c.edit(hpa)
+				require.NoError(t, cl.Update(context.Background(), hpa))
+			} else {
+				require.NoError(t, cl.Delete(context.Background(), hpa))
+			}
+
+			// do testing function
+			repaired, err := SyncHpa(cl, options)
+
+			// verify by cases
+			require.NoError(t, err)
+			require.Equal(t, c.expectedRepaired, repaired)
+			result, err := GetHpa(cl, "test-scsc-hpa", "test-ns")
+			require.NoError(t, err)
+			require.NotNil(t, result)
+			require.Equal(t, max, result.Spec.MaxReplicas)
+			require.Equal(t, "test-deploy", result.Spec.ScaleTargetRef.Name)
+			require.Equal(t, []metav1.OwnerReference{*owner}, result.OwnerReferences)
+			// the repaired HPA isn't reported again
+			repaired, err = SyncHpa(cl, options)
+			require.NoError(t, err)
+			require.False(t, repaired)
+		})
+	}
+}
+
+func TestHpaChanged(t *testing.T) {
+	max := int32(3)
+	editedMax := int32(10)
+	tc := map[string]struct {
+		edit     func(*autov2beta2.HorizontalPodAutoscaler)
+		expected bool
+	}{
+		"status changed": {
+			edit: func(hpa *autov2beta2.HorizontalPodAutoscaler) {
+				hpa.Status.CurrentReplicas = 2
+			},
+			expected: false,
+		},
+		"spec changed": {
+			edit: func(hpa *autov2beta2.HorizontalPodAutoscaler) {
+				hpa.Spec.MaxReplicas = editedMax
+			},
+			expected: true,
+		},
+		"owner references removed": {
+			edit: func(hpa *autov2beta2.HorizontalPodAutoscaler) {
+				hpa.OwnerReferences = nil
+			},
+			expected: true,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			oldHpa := &autov2beta2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "test-scsc-hpa",
+					Namespace:       "test-ns",
+					OwnerReferences: []metav1.OwnerReference{{Kind: "ScheduledScaler", Name: "test-scsc"}},
+				},
+				Spec: autov2beta2.HorizontalPodAutoscalerSpec{MaxReplicas: max},
+			}
+			newHpa := oldHpa.DeepCopy()
+			c.edit(newHpa)
+
+			// do testing function
+			changed := HpaChanged(oldHpa, newHpa)
+
+			// verify by cases
+			require.Equal(t, c.expected, changed)
+		})
+	}
+}
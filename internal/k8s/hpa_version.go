@@ -0,0 +1,255 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	autov1 "k8s.io/api/autoscaling/v1"
+	autov2beta2 "k8s.io/api/autoscaling/v2beta2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// hpaVersions are versions of autoscaling API which HPAs can be built with, in the order of preference.
+// autoscaling/v2 has the same schema as v2beta2, and v1 supports only CPU utilization
+var hpaVersions = []string{"v2", "v2beta2", "v1"}
+
+// hpaGroupVersion is the autoscaling API version which HPAs are read and written with.
+// HPAs are handled as v2beta2 in the operator, and converted from/to this version on the wire
+var hpaGroupVersion = struct {
+	sync.RWMutex
+	gv schema.GroupVersion
+}{gv: autov2beta2.SchemeGroupVersion}
+
+// DetectHpaGroupVersion returns the most preferred autoscaling API version which the server offers
+func DetectHpaGroupVersion(cl discovery.ServerGroupsInterface) (schema.GroupVersion, error) {
+	groups, err := cl.ServerGroups()
+	if err != nil {
+		return schema.GroupVersion{}, fmt.Errorf("Couldn't discover API groups: %v", err)
+	}
+
+	for _, group := range groups.Groups {
+		if group.Name != autov1.GroupName {
+			continue
+		}
+		for _, version := range hpaVersions {
+			for _, served := range group.Versions {
+				if served.Version == version {
+					return schema.GroupVersion{Group: autov1.GroupName, Version: version}, nil
+				}
+			}
+		}
+	}
+
+	return schema.GroupVersion{}, fmt.Errorf("Server offers none of autoscaling API versions %v", hpaVersions)
+}
+
+// SetHpaGroupVersion sets the autoscaling API version which HPAs are read and written with. It's v2beta2 by default
+func SetHpaGroupVersion(gv schema.GroupVersion) {
+	hpaGroupVersion.Lock()
+	defer hpaGroupVersion.Unlock()
+	hpaGroupVersion.gv = gv
+}
+
+// GetHpaGroupVersion returns the autoscaling API version which HPAs are read and written with
+func GetHpaGroupVersion() schema.GroupVersion {
+	hpaGroupVersion.RLock()
+	defer hpaGroupVersion.RUnlock()
+	return hpaGroupVersion.gv
+}
+
+// NewHpaObject returns an empty HPA of the autoscaling API version. autoscaling/v2 is unstructured,
+// because it has no type in the client library
+func NewHpaObject() runtime.Object {
+	gv := GetHpaGroupVersion()
+	switch gv.Version {
+	case "v1":
+		return &autov1.HorizontalPodAutoscaler{}
+	case "v2beta2":
+		return &autov2beta2.HorizontalPodAutoscaler{}
+	default:
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gv.WithKind("HorizontalPodAutoscaler"))
+		return u
+	}
+}
+
+func newHpaListObject() runtime.Object {
+	gv := GetHpaGroupVersion()
+	switch gv.Version {
+	case "v1":
+		return &autov1.HorizontalPodAutoscalerList{}
+	case "v2beta2":
+		return &autov2beta2.HorizontalPodAutoscalerList{}
+	default:
+		u := &unstructured.UnstructuredList{}
+		u.SetGroupVersionKind(gv.WithKind("HorizontalPodAutoscalerList"))
+		return u
+	}
+}
+
+// toHpaObject converts the HPA into the autoscaling API version
+func toHpaObject(hpa *autov2beta2.HorizontalPodAutoscaler) (runtime.Object, error) {
+	gv := GetHpaGroupVersion()
+	switch gv.Version {
+	case "v1":
+		return toV1Hpa(hpa)
+	case "v2beta2":
+		return hpa.DeepCopy(), nil
+	default:
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(hpa)
+		if err != nil {
+			return nil, err
+		}
+		// status is written only by the HPA controller
+		unstructured.RemoveNestedField(content, "status")
+		u := &unstructured.Unstructured{Object: content}
+		u.SetGroupVersionKind(gv.WithKind("HorizontalPodAutoscaler"))
+		return u, nil
+	}
+}
+
+// fromHpaObject converts the HPA of the autoscaling API version into v2beta2
+func fromHpaObject(obj runtime.Object) (*autov2beta2.HorizontalPodAutoscaler, error) {
+	switch o := obj.(type) {
+	case *autov1.HorizontalPodAutoscaler:
+		return fromV1Hpa(o), nil
+	case *autov2beta2.HorizontalPodAutoscaler:
+		return o, nil
+	case *unstructured.Unstructured:
+		hpa := &autov2beta2.HorizontalPodAutoscaler{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(o.UnstructuredContent(), hpa); err != nil {
+			return nil, err
+		}
+		return hpa, nil
+	default:
+		return nil, fmt.Errorf("%T isn't an HPA", obj)
+	}
+}
+
+// toV1Hpa converts the HPA into autoscaling/v1, which has no behavior and only CPU utilization metric
+func toV1Hpa(hpa *autov2beta2.HorizontalPodAutoscaler) (*autov1.HorizontalPodAutoscaler, error) {
+	if hpa.Spec.Behavior != nil {
+		return nil, fmt.Errorf("Behavior of HPA %s isn't supported by autoscaling/v1", hpa.Name)
+	}
+
+	v1Hpa := &autov1.HorizontalPodAutoscaler{
+		ObjectMeta: hpa.ObjectMeta,
+		Spec: autov1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autov1.CrossVersionObjectReference{
+				Kind:       hpa.Spec.ScaleTargetRef.Kind,
+				Name:       hpa.Spec.ScaleTargetRef.Name,
+				APIVersion: hpa.Spec.ScaleTargetRef.APIVersion,
+			},
+			MinReplicas: hpa.Spec.MinReplicas,
+			MaxReplicas: hpa.Spec.MaxReplicas,
+		},
+	}
+	for _, metric := range hpa.Spec.Metrics {
+		if metric.Type != autov2beta2.ResourceMetricSourceType || metric.Resource == nil || metric.Resource.Name != v1.ResourceCPU ||
+			metric.Resource.Target.Type != autov2beta2.UtilizationMetricType || v1Hpa.Spec.TargetCPUUtilizationPercentage != nil {
+			return nil, fmt.Errorf("Metrics of HPA %s aren't supported by autoscaling/v1, which supports only a CPU utilization metric", hpa.Name)
+		}
+		v1Hpa.Spec.TargetCPUUtilizationPercentage = metric.Resource.Target.AverageUtilization
+	}
+	return v1Hpa, nil
+}
+
+func fromV1Hpa(v1Hpa *autov1.HorizontalPodAutoscaler) *autov2beta2.HorizontalPodAutoscaler {
+	hpa := &autov2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: v1Hpa.ObjectMeta,
+		Spec: autov2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autov2beta2.CrossVersionObjectReference{
+				Kind:       v1Hpa.Spec.ScaleTargetRef.Kind,
+				Name:       v1Hpa.Spec.ScaleTargetRef.Name,
+				APIVersion: v1Hpa.Spec.ScaleTargetRef.APIVersion,
+			},
+			MinReplicas: v1Hpa.Spec.MinReplicas,
+			MaxReplicas: v1Hpa.Spec.MaxReplicas,
+		},
+	}
+	if utilization := v1Hpa.Spec.TargetCPUUtilizationPercentage; utilization != nil {
+		hpa.Spec.Metrics = []autov2beta2.MetricSpec{
+			{
+				Type: autov2beta2.ResourceMetricSourceType,
+				Resource: &autov2beta2.ResourceMetricSource{
+					Name: v1.ResourceCPU,
+					Target: autov2beta2.MetricTarget{
+						Type:               autov2beta2.UtilizationMetricType,
+						AverageUtilization: utilization,
+					},
+				},
+			},
+		}
+	}
+	return hpa
+}
+
+// getHpa gets the HPA with the autoscaling API version. It returns nil if the HPA doesn't exist
+func getHpa(cl client.Client, key client.ObjectKey) (*autov2beta2.HorizontalPodAutoscaler, error) {
+	obj := NewHpaObject()
+	if err := cl.Get(context.Background(), key, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return fromHpaObject(obj)
+}
+
+func listHpas(cl client.Client, opts ...client.ListOption) ([]autov2beta2.HorizontalPodAutoscaler, error) {
+	list := newHpaListObject()
+	if err := cl.List(context.Background(), list, opts...); err != nil {
+		return nil, err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+
+	hpas := make([]autov2beta2.HorizontalPodAutoscaler, 0, len(items))
+	for _, item := range items {
+		hpa, err := fromHpaObject(item)
+		if err != nil {
+			return nil, err
+		}
+		hpas = append(hpas, *hpa)
+	}
+	return hpas, nil
+}
+
+func createHpa(cl client.Client, hpa *autov2beta2.HorizontalPodAutoscaler) error {
+	obj, err := toHpaObject(hpa)
+	if err != nil {
+		return err
+	}
+	return cl.Create(context.Background(), obj)
+}
+
+// patchHpa patches the HPA with the difference from the original
+func patchHpa(cl client.Client, original, hpa *autov2beta2.HorizontalPodAutoscaler) error {
+	originalObj, err := toHpaObject(original)
+	if err != nil {
+		return err
+	}
+	obj, err := toHpaObject(hpa)
+	if err != nil {
+		return err
+	}
+	return cl.Patch(context.Background(), obj, client.MergeFrom(originalObj))
+}
+
+func deleteHpa(cl client.Client, hpa *autov2beta2.HorizontalPodAutoscaler) error {
+	obj, err := toHpaObject(hpa)
+	if err != nil {
+		return err
+	}
+	return cl.Delete(context.Background(), obj)
+}
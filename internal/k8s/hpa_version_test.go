@@ -0,0 +1,170 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	autov1 "k8s.io/api/autoscaling/v1"
+	autov2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	fakeDiscovery "k8s.io/client-go/discovery/fake"
+	coreTesting "k8s.io/client-go/testing"
+	fakeCli "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDetectHpaGroupVersion(t *testing.T) {
+	tc := map[string]struct {
+		served          []string
+		expectedVersion string
+		errorOccurs     bool
+	}{
+		"v2 is preferred": {
+			served:          []string{"autoscaling/v1", "autoscaling/v2", "autoscaling/v2beta2"},
+			expectedVersion: "v2",
+		},
+		"v2beta2 without v2": {
+			served:          []string{"autoscaling/v1", "autoscaling/v2beta1", "autoscaling/v2beta2"},
+			expectedVersion: "v2beta2",
+		},
+		"only v1": {
+			served:          []string{"autoscaling/v1"},
+			expectedVersion: "v1",
+		},
+		"no autoscaling": {
+			served:      []string{"apps/v1"},
+			errorOccurs: true,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			discovery := &fakeDiscovery.FakeDiscovery{Fake: &coreTesting.Fake{}}
+			for _, gv := range c.served {
+				discovery.Resources = append(discovery.Resources, &metav1.APIResourceList{
+					GroupVersion: gv,
+					APIResources: []metav1.APIResource{{Name: "horizontalpodautoscalers", Kind: "HorizontalPodAutoscaler"}},
+				})
+			}
+
+			// do testing function
+			gv, err := DetectHpaGroupVersion(discovery)
+
+			// verify by cases
+			if c.errorOccurs {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, schema.GroupVersion{Group: "autoscaling", Version: c.expectedVersion}, gv)
+		})
+	}
+}
+
+func TestUpdateHpa_V1(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(autov1.AddToScheme(s))
+	SetHpaGroupVersion(autov1.SchemeGroupVersion)
+	defer SetHpaGroupVersion(autov2beta2.SchemeGroupVersion)
+
+	min := int32(1)
+	max := int32(3)
+	utilization := int32(70)
+	tc := map[string]struct {
+		metrics             []autov2beta2.MetricSpec
+		behavior            *autov2beta2.HorizontalPodAutoscalerBehavior
+		expectedUtilization int32
+		errorOccurs         bool
+	}{
+		"default metrics": {
+			expectedUtilization: 50,
+		},
+		"cpu utilization": {
+			metrics: []autov2beta2.MetricSpec{
+				{
+					Type: autov2beta2.ResourceMetricSourceType,
+					Resource: &autov2beta2.ResourceMetricSource{
+						Name:   "cpu",
+						Target: autov2beta2.MetricTarget{Type: autov2beta2.UtilizationMetricType, AverageUtilization: &utilization},
+					},
+				},
+			},
+			expectedUtilization: utilization,
+		},
+		"memory isn't supported": {
+			metrics: []autov2beta2.MetricSpec{
+				{
+					Type: autov2beta2.ResourceMetricSourceType,
+					Resource: &autov2beta2.ResourceMetricSource{
+						Name:   "memory",
+						Target: autov2beta2.MetricTarget{Type: autov2beta2.UtilizationMetricType, AverageUtilization: &utilization},
+					},
+				},
+			},
+			errorOccurs: true,
+		},
+		"behavior isn't supported": {
+			behavior:    &autov2beta2.HorizontalPodAutoscalerBehavior{},
+			errorOccurs: true,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			cl := fakeCli.NewFakeClientWithScheme(s)
+
+			// do testing function
+			_, err := UpdateHpa(cl, &HpaValidationOptions{
+				Namespace:           "test-ns",
+				Target:              scscv1.SchedulingTarget{Name: "test-deploy"},
+				ScheduledScalerName: "test-scsc",
+				MinReplicas:         &min,
+				MaxReplicas:         &max,
+				Metrics:             c.metrics,
+				Behavior:            c.behavior,
+			})
+
+			// verify by cases
+			if c.errorOccurs {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			hpa, err := GetHpa(cl, "test-scsc-hpa", "test-ns")
+			require.NoError(t, err)
+			require.Equal(t, max, hpa.Spec.MaxReplicas)
+			require.Equal(t, c.expectedUtilization, *hpa.Spec.Metrics[0].Resource.Target.AverageUtilization)
+		})
+	}
+}
+
+func TestToHpaObject_V2(t *testing.T) {
+	// set test case
+	SetHpaGroupVersion(schema.GroupVersion{Group: "autoscaling", Version: "v2"})
+	defer SetHpaGroupVersion(autov2beta2.SchemeGroupVersion)
+	min := int32(1)
+	hpa := &autov2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-hpa", Namespace: "test-ns"},
+		Spec: autov2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autov2beta2.CrossVersionObjectReference{Kind: "Deployment", Name: "test-deploy", APIVersion: "apps/v1"},
+			MinReplicas:    &min,
+			MaxReplicas:    3,
+		},
+	}
+
+	// do testing function
+	obj, err := toHpaObject(hpa)
+	require.NoError(t, err)
+	converted, err := fromHpaObject(obj)
+
+	// verify by cases
+	require.NoError(t, err)
+	require.Equal(t, "autoscaling/v2", obj.(*unstructured.Unstructured).GetAPIVersion())
+	require.Equal(t, hpa.Spec, converted.Spec)
+}
@@ -0,0 +1,99 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/scale"
+)
+
+// ScaleClient reads and writes replicas of any workload through its scale subresource, like kubectl scale does
+type ScaleClient interface {
+	GetReplicas(target scscv1.SchedulingTarget, namespace string) (int32, error)
+	ScaleReplicas(target scscv1.SchedulingTarget, namespace string, replicas *int32) error
+}
+
+type ScaleClientImpl struct {
+	scales scale.ScalesGetter
+	mapper meta.RESTMapper
+}
+
+func NewScaleClient(scales scale.ScalesGetter, mapper meta.RESTMapper) ScaleClient {
+	return &ScaleClientImpl{
+		scales: scales,
+		mapper: mapper,
+	}
+}
+
+// NewScaleClientForConfig creates ScaleClient which resolves scale kinds of targets by discovery
+func NewScaleClientForConfig(cfg *rest.Config, mapper meta.RESTMapper) (ScaleClient, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	scales, err := scale.NewForConfig(cfg, mapper, dynamic.LegacyAPIPathResolverFunc, scale.NewDiscoveryScaleKindResolver(discoveryClient))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewScaleClient(scales, mapper), nil
+}
+
+func (c *ScaleClientImpl) GetReplicas(target scscv1.SchedulingTarget, namespace string) (int32, error) {
+	resource, err := c.groupResource(target)
+	if err != nil {
+		return 0, err
+	}
+
+	targetScale, err := c.scales.Scales(namespace).Get(context.Background(), resource, target.Name, metav1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+
+	return targetScale.Spec.Replicas, nil
+}
+
+func (c *ScaleClientImpl) ScaleReplicas(target scscv1.SchedulingTarget, namespace string, replicas *int32) error {
+	if replicas == nil {
+		return fmt.Errorf("Replicas must be set to scale %s", target.Name)
+	}
+
+	resource, err := c.groupResource(target)
+	if err != nil {
+		return err
+	}
+
+	targetScale, err := c.scales.Scales(namespace).Get(context.Background(), resource, target.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	targetScale.Spec.Replicas = *replicas
+	if _, err = c.scales.Scales(namespace).Update(context.Background(), resource, targetScale, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ScaleClientImpl) groupResource(target scscv1.SchedulingTarget) (schema.GroupResource, error) {
+	gvk, err := GetTargetGroupVersionKind(target)
+	if err != nil {
+		return schema.GroupResource{}, err
+	}
+
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return schema.GroupResource{}, fmt.Errorf("Couldn't find resource of %s: %v", gvk.String(), err)
+	}
+
+	return mapping.Resource.GroupResource(), nil
+}
@@ -0,0 +1,80 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	autov1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakeScale "k8s.io/client-go/scale/fake"
+	coreTesting "k8s.io/client-go/testing"
+)
+
+func TestScaleClient_ScaleReplicas(t *testing.T) {
+	replicas := int32(3)
+	tc := map[string]struct {
+		target           scscv1.SchedulingTarget
+		expectedResource schema.GroupResource
+		errorOccurs      bool
+	}{
+		"default deployment": {
+			target:           scscv1.SchedulingTarget{Name: "test-target"},
+			expectedResource: schema.GroupResource{Group: "apps", Resource: "deployments"},
+		},
+		"statefulset": {
+			target:           scscv1.SchedulingTarget{Kind: "StatefulSet", Name: "test-target"},
+			expectedResource: schema.GroupResource{Group: "apps", Resource: "statefulsets"},
+		},
+		"custom resource": {
+			target:           scscv1.SchedulingTarget{Kind: "Rollout", APIVersion: "argoproj.io/v1alpha1", Name: "test-target"},
+			expectedResource: schema.GroupResource{Group: "argoproj.io", Resource: "rollouts"},
+		},
+		"unknown kind": {
+			target:      scscv1.SchedulingTarget{Kind: "Unknown", Name: "test-target"},
+			errorOccurs: true,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			mapper := meta.NewDefaultRESTMapper(nil)
+			mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+			mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"}, meta.RESTScopeNamespace)
+			mapper.Add(schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}, meta.RESTScopeNamespace)
+
+			var updatedResource schema.GroupResource
+			updated := &autov1.Scale{}
+			scales := &fakeScale.FakeScaleClient{}
+			scales.AddReactor("get", "*", func(action coreTesting.Action) (bool, runtime.Object, error) {
+				return true, &autov1.Scale{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-target", Namespace: "test-ns"},
+					Spec:       autov1.ScaleSpec{Replicas: 1},
+				}, nil
+			})
+			scales.AddReactor("update", "*", func(action coreTesting.Action) (bool, runtime.Object, error) {
+				updateAction := action.(coreTesting.UpdateAction)
+				updatedResource = updateAction.GetResource().GroupResource()
+				updated = updateAction.GetObject().(*autov1.Scale)
+				return true, updated, nil
+			})
+			testClient := NewScaleClient(scales, mapper)
+
+			// do testing function
+			err := testClient.ScaleReplicas(c.target, "test-ns", &replicas)
+
+			// verify by cases
+			if c.errorOccurs {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedResource, updatedResource)
+			require.Equal(t, replicas, updated.Spec.Replicas)
+		})
+	}
+}
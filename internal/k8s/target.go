@@ -0,0 +1,152 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	KindDeployment = "Deployment"
+
+	defaultTargetAPIVersion = "apps/v1"
+)
+
+// GetTargetKind returns kind of the scaling target. Deployment is default
+func GetTargetKind(target scscv1.SchedulingTarget) string {
+	if target.Kind == "" {
+		return KindDeployment
+	}
+	return target.Kind
+}
+
+// GetTargetAPIVersion returns api version of the scaling target. apps/v1 is default
+func GetTargetAPIVersion(target scscv1.SchedulingTarget) string {
+	if target.APIVersion == "" {
+		return defaultTargetAPIVersion
+	}
+	return target.APIVersion
+}
+
+// GetTargetGroupVersionKind returns GroupVersionKind of the scaling target
+func GetTargetGroupVersionKind(target scscv1.SchedulingTarget) (schema.GroupVersionKind, error) {
+	gv, err := schema.ParseGroupVersion(GetTargetAPIVersion(target))
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+
+	return gv.WithKind(GetTargetKind(target)), nil
+}
+
+// ListTargets resolves the target into every matching workload. A target with name is resolved into itself
+func ListTargets(cl client.Client, target scscv1.SchedulingTarget, namespace string) ([]scscv1.SchedulingTarget, error) {
+	if target.Selector == nil {
+		return []scscv1.SchedulingTarget{target}, nil
+	}
+
+	gvk, err := GetTargetGroupVersionKind(target)
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(target.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	workloads := &unstructured.UnstructuredList{}
+	workloads.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := cl.List(context.Background(), workloads, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("Listing targets failed: %v", err)
+	}
+
+	targets := make([]scscv1.SchedulingTarget, 0, len(workloads.Items))
+	for _, workload := range workloads.Items {
+		targets = append(targets, scscv1.SchedulingTarget{
+			Kind:       target.Kind,
+			APIVersion: target.APIVersion,
+			Name:       workload.GetName(),
+		})
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Name < targets[j].Name
+	})
+
+	return targets, nil
+}
+
+// ListTargetReplicas returns replicas of every workload of the target, read by the client without calling the scale subresource.
+// Workloads are read as typed objects, so that the cached client of the manager serves them. Kinds which aren't built in
+// return nothing, because unstructured objects aren't cached
+func ListTargetReplicas(cl client.Reader, target scscv1.SchedulingTarget, namespace string) ([]scscv1.TargetReplicas, error) {
+	gvk, err := GetTargetGroupVersionKind(target)
+	if err != nil {
+		return nil, err
+	}
+	listGVK := gvk.GroupVersion().WithKind(gvk.Kind + "List")
+	if !clientgoscheme.Scheme.Recognizes(gvk) || !clientgoscheme.Scheme.Recognizes(listGVK) {
+		return nil, nil
+	}
+
+	var workloads []runtime.Object
+	if target.Selector == nil {
+		workload, err := clientgoscheme.Scheme.New(gvk)
+		if err != nil {
+			return nil, err
+		}
+		if err := cl.Get(context.Background(), client.ObjectKey{Name: target.Name, Namespace: namespace}, workload); err != nil {
+			if errors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("Getting target failed: %v", err)
+		}
+		workloads = []runtime.Object{workload}
+	} else {
+		selector, err := metav1.LabelSelectorAsSelector(target.Selector)
+		if err != nil {
+			return nil, err
+		}
+		list, err := clientgoscheme.Scheme.New(listGVK)
+		if err != nil {
+			return nil, err
+		}
+		if err := cl.List(context.Background(), list, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, fmt.Errorf("Listing targets failed: %v", err)
+		}
+		if workloads, err = meta.ExtractList(list); err != nil {
+			return nil, err
+		}
+	}
+
+	replicas := make([]scscv1.TargetReplicas, 0, len(workloads))
+	for _, workload := range workloads {
+		accessor, err := meta.Accessor(workload)
+		if err != nil {
+			return nil, err
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(workload)
+		if err != nil {
+			return nil, err
+		}
+		current, found, err := unstructured.NestedInt64(content, "spec", "replicas")
+		if err != nil || !found {
+			continue
+		}
+		replicas = append(replicas, scscv1.TargetReplicas{Name: accessor.GetName(), Replicas: int32(current)})
+	}
+	sort.Slice(replicas, func(i, j int) bool {
+		return replicas[i].Name < replicas[j].Name
+	})
+
+	return replicas, nil
+}
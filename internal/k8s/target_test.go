@@ -0,0 +1,75 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	fakeCli "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestListTargetReplicas(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(appsv1.AddToScheme(s))
+
+	newDeployment := func(name string, replicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test-ns",
+				Labels:    map[string]string{"tier": "worker"},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+			},
+		}
+	}
+	tc := map[string]struct {
+		target   scscv1.SchedulingTarget
+		expected []scscv1.TargetReplicas
+	}{
+		"target with name": {
+			target:   scscv1.SchedulingTarget{Name: "worker-2"},
+			expected: []scscv1.TargetReplicas{{Name: "worker-2", Replicas: 3}},
+		},
+		"target doesn't exist": {
+			target: scscv1.SchedulingTarget{Name: "missing-deploy"},
+		},
+		"target with selector": {
+			target: scscv1.SchedulingTarget{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"tier": "worker"},
+				},
+			},
+			expected: []scscv1.TargetReplicas{{Name: "worker-1", Replicas: 2}, {Name: "worker-2", Replicas: 3}},
+		},
+		"custom resource isn't read": {
+			target: scscv1.SchedulingTarget{Kind: "Rollout", APIVersion: "argoproj.io/v1alpha1", Name: "worker-1"},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			cl := fakeCli.NewFakeClientWithScheme(s)
+			require.NoError(t, cl.Create(context.Background(), newDeployment("worker-2", 3)))
+			require.NoError(t, cl.Create(context.Background(), newDeployment("worker-1", 2)))
+
+			// do testing function
+			replicas, err := ListTargetReplicas(cl, c.target, "test-ns")
+
+			// verify by cases
+			require.NoError(t, err)
+			if len(c.expected) == 0 {
+				require.Empty(t, replicas)
+				return
+			}
+			require.Equal(t, c.expected, replicas)
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package oneoff
+
+import "time"
+
+// Schedule is due only at the time. It's shared by crons and the validator
+type Schedule struct {
+	At time.Time
+}
+
+func (s Schedule) Next(t time.Time) time.Time {
+	if s.At.After(t) {
+		return s.At
+	}
+	return time.Time{}
+}
@@ -0,0 +1,42 @@
+package oneoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	at := time.Date(2026, 12, 25, 9, 0, 0, 0, time.UTC)
+	tc := map[string]struct {
+		from     time.Time
+		expected time.Time
+	}{
+		"before the time": {
+			from:     at.Add(-time.Minute),
+			expected: at,
+		},
+		"at the time": {
+			from:     at,
+			expected: time.Time{},
+		},
+		"after the time": {
+			from:     at.Add(time.Minute),
+			expected: time.Time{},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			s := Schedule{At: at}
+
+			// do testing function
+			next := s.Next(c.from)
+
+			// verify by cases
+			require.Equal(t, c.expected, next)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"fmt"
+	"sync"
+
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// FakeScaleClient is a scale client for testing, which keeps replicas of targets in memory.
+// It's safe for concurrent use
+type FakeScaleClient struct {
+	Replicas map[string]int32
+	// Err is returned by every call if it's set
+	Err   error
+	mutex sync.Mutex
+}
+
+// NewFakeScaleClient returns an empty FakeScaleClient
+func NewFakeScaleClient() *FakeScaleClient {
+	return &FakeScaleClient{Replicas: make(map[string]int32)}
+}
+
+// Set sets replicas of the target
+func (f *FakeScaleClient) Set(target scscv1.SchedulingTarget, namespace string, replicas int32) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.Replicas[f.key(target, namespace)] = replicas
+}
+
+// GetReplicas returns replicas of the target
+func (f *FakeScaleClient) GetReplicas(target scscv1.SchedulingTarget, namespace string) (int32, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.Err != nil {
+		return 0, f.Err
+	}
+	replicas, exist := f.Replicas[f.key(target, namespace)]
+	if !exist {
+		return 0, errors.NewNotFound(schema.GroupResource{Resource: target.Kind}, target.Name)
+	}
+	return replicas, nil
+}
+
+// ScaleReplicas updates replicas of the target
+func (f *FakeScaleClient) ScaleReplicas(target scscv1.SchedulingTarget, namespace string, replicas *int32) error {
+	if _, err := f.GetReplicas(target, namespace); err != nil {
+		return err
+	}
+	if replicas == nil {
+		return fmt.Errorf("Replicas must be set to scale %s", target.Name)
+	}
+	f.Set(target, namespace, *replicas)
+	return nil
+}
+
+func (f *FakeScaleClient) key(target scscv1.SchedulingTarget, namespace string) string {
+	kind := target.Kind
+	if kind == "" {
+		kind = "Deployment"
+	}
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, target.Name)
+}
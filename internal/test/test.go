@@ -16,17 +16,24 @@
 
 package test
 
-import "github.com/go-logr/logr"
+import (
+	"sync"
 
-// FakeLogger is a logger for testing
+	"github.com/go-logr/logr"
+)
+
+// FakeLogger is a logger for testing. It's safe for concurrent use
 type FakeLogger struct {
 	Infos     []string
 	Errors    []error
 	ErrorMsgs []string
+	mutex     sync.Mutex
 }
 
 // Clear clears the logger
 func (f *FakeLogger) Clear() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
 	f.Infos = nil
 	f.Errors = nil
 	f.ErrorMsgs = nil
@@ -37,11 +44,15 @@ func (f *FakeLogger) Enabled() bool { return true }
 
 // Info logs info level
 func (f *FakeLogger) Info(msg string, _ ...interface{}) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
 	f.Infos = append(f.Infos, msg)
 }
 
 // Error logs error level
 func (f *FakeLogger) Error(err error, msg string, _ ...interface{}) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
 	f.Errors = append(f.Errors, err)
 	f.ErrorMsgs = append(f.ErrorMsgs, msg)
 }
@@ -18,10 +18,13 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -29,6 +32,8 @@ import (
 
 	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
 	"github.com/tmax-cloud/scheduled-scaler-operator/controllers"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/k8s"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/webhook"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -47,14 +52,30 @@ func init() {
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
+	var defaultTimeZone string
+	var maxConcurrentReconciles int
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&defaultTimeZone, "default-time-zone", "",
+		"The time zone filled in ScheduledScalers and ScheduleCalendars which have no time zone. "+
+			"Nothing is filled in when it's empty.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"The maximum number of ScheduledScalers which are reconciled concurrently.")
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 
+	if _, err := time.LoadLocation(defaultTimeZone); err != nil {
+		setupLog.Error(err, "unable to load default time zone")
+		os.Exit(1)
+	}
+	if maxConcurrentReconciles < 1 {
+		setupLog.Error(fmt.Errorf("%d is less than 1", maxConcurrentReconciles), "invalid max concurrent reconciles")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:             scheme,
 		MetricsBindAddress: metricsAddr,
@@ -67,14 +88,68 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&controllers.ScheduledScalerReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("ScheduledScaler"),
-		Scheme: mgr.GetScheme(),
-	}).Init().SetupWithManager(mgr); err != nil {
+	// HPAs are built with the autoscaling API version which the server offers
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
+		os.Exit(1)
+	}
+	hpaGroupVersion, err := k8s.DetectHpaGroupVersion(discoveryClient)
+	if err != nil {
+		setupLog.Error(err, "unable to detect autoscaling API version")
+		os.Exit(1)
+	}
+	k8s.SetHpaGroupVersion(hpaGroupVersion)
+	setupLog.Info("HPAs are built with " + hpaGroupVersion.String())
+
+	scaleClient, err := k8s.NewScaleClientForConfig(mgr.GetConfig(), mgr.GetRESTMapper())
+	if err != nil {
+		setupLog.Error(err, "unable to create scale client")
+		os.Exit(1)
+	}
+
+	scheduledScalerReconciler := (&controllers.ScheduledScalerReconciler{
+		Client:                  mgr.GetClient(),
+		Log:                     ctrl.Log.WithName("controllers").WithName("ScheduledScaler"),
+		Scheme:                  mgr.GetScheme(),
+		ScaleClient:             scaleClient,
+		Recorder:                mgr.GetEventRecorderFor("scheduledscaler-controller"),
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+	}).Init()
+	if err = scheduledScalerReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ScheduledScaler")
 		os.Exit(1)
 	}
+	// crons run only in the leader, and are rebuilt from the API server when the leadership is handed over
+	if err = mgr.Add(scheduledScalerReconciler.CronManager()); err != nil {
+		setupLog.Error(err, "unable to add cron manager")
+		os.Exit(1)
+	}
+	if err = (&controllers.ScheduleCalendarReconciler{
+		Client:      mgr.GetClient(),
+		Log:         ctrl.Log.WithName("controllers").WithName("ScheduleCalendar"),
+		Scheme:      mgr.GetScheme(),
+		CronManager: scheduledScalerReconciler.CronManager(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ScheduleCalendar")
+		os.Exit(1)
+	}
+	// crons are rebuilt when ConfigMaps of blackout dates are changed
+	if err = (&controllers.BlackoutReconciler{
+		Client:      mgr.GetClient(),
+		Log:         ctrl.Log.WithName("controllers").WithName("Blackout"),
+		Scheme:      mgr.GetScheme(),
+		CronManager: scheduledScalerReconciler.CronManager(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Blackout")
+		os.Exit(1)
+	}
+	// webhook needs serving certs, so it can be disabled to run the manager locally
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		(&webhook.ScheduledScalerDefaulter{DefaultTimeZone: defaultTimeZone}).SetupWithManager(mgr)
+		(&webhook.ScheduledScalerValidator{}).SetupWithManager(mgr)
+		(&webhook.ScheduleCalendarDefaulter{DefaultTimeZone: defaultTimeZone}).SetupWithManager(mgr)
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")
@@ -3,9 +3,17 @@ package apimanager
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/k8s"
 	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/validator"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -21,18 +29,245 @@ func GetNamespacedName(scsc scscv1.ScheduledScaler) string {
 	return fmt.Sprintf("%s-%s", scsc.Namespace, scsc.Name)
 }
 
+// UpdateStatus updates phase, message and reason of status, and sets the given conditions and observed generation.
+// Other fields of status are kept, including conditions written by crons after scsc was read
 func UpdateStatus(cl client.Client, scsc *scscv1.ScheduledScaler, status scscv1.ScheduledScalerStatus) error {
-	origin := client.MergeFrom(scsc)
-	patch := scsc.DeepCopy()
-	patch.Status = status
-
-	if err := cl.Status().Patch(context.TODO(), patch, origin); err != nil {
+	key := types.NamespacedName{Name: scsc.Name, Namespace: scsc.Namespace}
+	err := updateLatestStatus(cl, key, func(latest *scscv1.ScheduledScaler) bool {
+		latest.Status.Phase = status.Phase
+		latest.Status.Message = status.Message
+		latest.Status.Reason = status.Reason
+		if status.ObservedGeneration != 0 {
+			latest.Status.ObservedGeneration = status.ObservedGeneration
+		}
+		for _, condition := range status.Conditions {
+			SetCondition(&latest.Status.Conditions, condition)
+		}
+		return true
+	})
+	if err != nil {
 		return fmt.Errorf("Couldn't update status: %v", err)
 	}
 
 	return nil
 }
 
-func Validate(scsc *scscv1.ScheduledScaler) bool {
+// updateLatestStatus reads the latest scsc and updates its status changed by mutate, retrying on conflicts.
+// The status isn't updated when mutate returns false, i.e. nothing is changed
+func updateLatestStatus(cl client.Client, key types.NamespacedName, mutate func(*scscv1.ScheduledScaler) bool) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest := &scscv1.ScheduledScaler{}
+		if err := cl.Get(context.TODO(), key, latest); err != nil {
+			return err
+		}
+
+		if !mutate(latest) {
+			return nil
+		}
+		return cl.Status().Update(context.TODO(), latest)
+	})
+}
+
+// Validate returns errors of every invalid field of the spec
+func Validate(scsc *scscv1.ScheduledScaler) field.ErrorList {
 	return validator.New(*scsc).Validate()
 }
+
+// RecordOriginalReplicas records current replicas of targets which are not recorded yet, to restore them after deleting scsc.
+// They're added to the latest status, so that records written by others meanwhile are kept
+func RecordOriginalReplicas(cl client.Client, scaleCl k8s.ScaleClient, scsc *scscv1.ScheduledScaler) error {
+	targets, err := k8s.ListTargets(cl, scsc.Spec.Target, scsc.Namespace)
+	if err != nil {
+		return err
+	}
+
+	unrecorded := []scscv1.TargetReplicas{}
+	for _, target := range targets {
+		if GetOriginalReplicas(scsc, target.Name) != nil {
+			continue
+		}
+
+		replicas, err := scaleCl.GetReplicas(target, scsc.Namespace)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				// target not deployed yet doesn't have original replicas
+				continue
+			}
+			return err
+		}
+		unrecorded = append(unrecorded, scscv1.TargetReplicas{
+			Name:     target.Name,
+			Replicas: replicas,
+		})
+	}
+
+	if len(unrecorded) == 0 {
+		return nil
+	}
+
+	key := types.NamespacedName{Name: scsc.Name, Namespace: scsc.Namespace}
+	err = updateLatestStatus(cl, key, func(latest *scscv1.ScheduledScaler) bool {
+		changed := false
+		for _, original := range unrecorded {
+			if GetOriginalReplicas(latest, original.Name) == nil {
+				latest.Status.OriginalReplicas = append(latest.Status.OriginalReplicas, original)
+				changed = true
+			}
+		}
+		return changed
+	})
+	if err != nil {
+		return fmt.Errorf("Couldn't record original replicas: %v", err)
+	}
+
+	return nil
+}
+
+// GetOriginalReplicas returns recorded original replicas of the target. It returns nil if it's not recorded
+func GetOriginalReplicas(scsc *scscv1.ScheduledScaler, targetName string) *int32 {
+	for _, original := range scsc.Status.OriginalReplicas {
+		if original.Name == targetName {
+			replicas := original.Replicas
+			return &replicas
+		}
+	}
+	return nil
+}
+
+// UpdateCalendarStatus updates resolved blackout dates and expired one-off schedules of status, only when they're changed
+func UpdateCalendarStatus(cl client.Client, scsc *scscv1.ScheduledScaler, blackouts []string, expiredSchedules []int32) error {
+	if reflect.DeepEqual(scsc.Status.Blackouts, blackouts) && reflect.DeepEqual(scsc.Status.ExpiredSchedules, expiredSchedules) {
+		return nil
+	}
+
+	origin := client.MergeFrom(scsc.DeepCopy())
+	scsc.Status.Blackouts = blackouts
+	scsc.Status.ExpiredSchedules = expiredSchedules
+	if err := cl.Status().Patch(context.TODO(), scsc, origin); err != nil {
+		return fmt.Errorf("Couldn't update calendar status: %v", err)
+	}
+
+	return nil
+}
+
+// UpdateScheduleStatus updates states of schedule entries and expired one-off schedules of the latest scsc, only when they're changed.
+// Last run of an entry which hasn't run since the cron was rebuilt is kept from the previous status
+func UpdateScheduleStatus(cl client.Client, key types.NamespacedName, schedules []scscv1.ScheduleStatus, nextRunTime *metav1.Time, expiredSchedules []int32) error {
+	err := updateLatestStatus(cl, key, func(scsc *scscv1.ScheduledScaler) bool {
+		merged := make([]scscv1.ScheduleStatus, 0, len(schedules))
+		for _, schedule := range schedules {
+			if schedule.LastRunTime == nil {
+				schedule = WithPreviousRun(schedule, scsc.Status.Schedules)
+			}
+			merged = append(merged, schedule)
+		}
+
+		conditions := make([]scscv1.Condition, len(scsc.Status.Conditions))
+		copy(conditions, scsc.Status.Conditions)
+		SetCondition(&conditions, lastScaleCondition(merged, scsc.Generation))
+
+		if equality.Semantic.DeepEqual(scsc.Status.Schedules, merged) && equality.Semantic.DeepEqual(scsc.Status.NextRunTime, nextRunTime) &&
+			equality.Semantic.DeepEqual(scsc.Status.Conditions, conditions) && reflect.DeepEqual(scsc.Status.ExpiredSchedules, expiredSchedules) {
+			return false
+		}
+
+		scsc.Status.Schedules = merged
+		scsc.Status.NextRunTime = nextRunTime
+		scsc.Status.Conditions = conditions
+		scsc.Status.ExpiredSchedules = expiredSchedules
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("Couldn't update schedule status of %s: %v", key, err)
+	}
+
+	return nil
+}
+
+// WithPreviousRun returns the status whose last run is copied from the previous status of the same index and name
+func WithPreviousRun(schedule scscv1.ScheduleStatus, previous []scscv1.ScheduleStatus) scscv1.ScheduleStatus {
+	for _, p := range previous {
+		if p.Index != schedule.Index || p.Name != schedule.Name {
+			continue
+		}
+
+		schedule.LastRunTime = p.LastRunTime
+		schedule.LastScheduleTime = p.LastScheduleTime
+		schedule.LastResult = p.LastResult
+		schedule.LastError = p.LastError
+		schedule.Replicas = p.Replicas
+		schedule.MinReplicas = p.MinReplicas
+		schedule.MaxReplicas = p.MaxReplicas
+		schedule.HpaSpecHash = p.HpaSpecHash
+		break
+	}
+	return schedule
+}
+
+// ResolveCalendar returns a copy of scsc whose spec is merged with the referred ScheduleCalendar.
+// scsc itself is returned when it refers no calendar
+func ResolveCalendar(cl client.Client, scsc *scscv1.ScheduledScaler) (*scscv1.ScheduledScaler, error) {
+	ref := scsc.Spec.CalendarRef
+	if ref == nil {
+		return scsc, nil
+	}
+
+	calendar := &scscv1.ScheduleCalendar{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: ref.Name, Namespace: scsc.Namespace}, calendar); err != nil {
+		return nil, fmt.Errorf("Getting ScheduleCalendar %s failed: %v", ref.Name, err)
+	}
+
+	resolved := scsc.DeepCopy()
+	schedules := make([]scscv1.Schedule, 0, len(calendar.Spec.Schedule)+len(scsc.Spec.Schedule))
+	for _, schedule := range calendar.Spec.Schedule {
+		schedules = append(schedules, overrideSchedule(schedule, ref.Overrides))
+	}
+	resolved.Spec.Schedule = append(schedules, resolved.Spec.Schedule...)
+
+	if resolved.Spec.Default == nil && calendar.Spec.Default != nil {
+		defaultSchedule := overrideSchedule(*calendar.Spec.Default, ref.Overrides)
+		resolved.Spec.Default = &defaultSchedule
+	}
+
+	if resolved.Spec.TimeZone == "" {
+		resolved.Spec.TimeZone = calendar.Spec.TimeZone
+	}
+
+	if calendar.Spec.Blackout != nil {
+		if resolved.Spec.Blackout == nil {
+			resolved.Spec.Blackout = calendar.Spec.Blackout.DeepCopy()
+		} else {
+			resolved.Spec.Blackout.Dates = append(calendar.Spec.Blackout.Dates, resolved.Spec.Blackout.Dates...)
+			if resolved.Spec.Blackout.ConfigMapRef == nil {
+				resolved.Spec.Blackout.ConfigMapRef = calendar.Spec.Blackout.ConfigMapRef
+			}
+		}
+	}
+
+	return resolved, nil
+}
+
+// overrideSchedule returns the schedule whose replicas are replaced by the override of the same name
+func overrideSchedule(schedule scscv1.Schedule, overrides []scscv1.ScheduleOverride) scscv1.Schedule {
+	if schedule.Name == "" {
+		return schedule
+	}
+
+	for _, override := range overrides {
+		if override.Name != schedule.Name {
+			continue
+		}
+
+		if override.Replicas != nil {
+			schedule.Replicas = override.Replicas
+		}
+		if override.MinReplicas != nil {
+			schedule.MinReplicas = override.MinReplicas
+		}
+		if override.MaxReplicas != nil {
+			schedule.MaxReplicas = override.MaxReplicas
+		}
+	}
+
+	return schedule
+}
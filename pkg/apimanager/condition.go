@@ -0,0 +1,170 @@
+package apimanager
+
+import (
+	"fmt"
+
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/k8s"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SetCondition adds or updates the condition of the same type.
+// LastTransitionTime is changed only when the status of the condition is changed
+func SetCondition(conditions *[]scscv1.Condition, condition scscv1.Condition) {
+	existing := FindCondition(*conditions, condition.Type)
+	if existing == nil {
+		if condition.LastTransitionTime.IsZero() {
+			condition.LastTransitionTime = metav1.Now()
+		}
+		*conditions = append(*conditions, condition)
+		return
+	}
+
+	if existing.Status != condition.Status {
+		existing.Status = condition.Status
+		existing.LastTransitionTime = condition.LastTransitionTime
+		if existing.LastTransitionTime.IsZero() {
+			existing.LastTransitionTime = metav1.Now()
+		}
+	}
+	existing.Reason = condition.Reason
+	existing.Message = condition.Message
+	existing.ObservedGeneration = condition.ObservedGeneration
+}
+
+// FindCondition returns the condition of the type. It returns nil if it doesn't exist
+func FindCondition(conditions []scscv1.Condition, conditionType string) *scscv1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// TargetCondition returns TargetFound condition, checking every target of scsc exists
+func TargetCondition(cl client.Client, scaleCl k8s.ScaleClient, scsc *scscv1.ScheduledScaler) scscv1.Condition {
+	condition := scscv1.Condition{
+		Type:               scscv1.ConditionTargetFound,
+		Status:             scscv1.ConditionFalse,
+		ObservedGeneration: scsc.Generation,
+		Reason:             scscv1.ReasonTargetNotFound,
+	}
+
+	targets, err := k8s.ListTargets(cl, scsc.Spec.Target, scsc.Namespace)
+	if err != nil {
+		condition.Message = err.Error()
+		return condition
+	}
+	if len(targets) == 0 {
+		condition.Message = "No workload matches the selector"
+		return condition
+	}
+
+	for _, target := range targets {
+		if _, err := scaleCl.GetReplicas(target, scsc.Namespace); err != nil {
+			if errors.IsNotFound(err) {
+				condition.Message = fmt.Sprintf("Target %s doesn't exist", target.Name)
+			} else {
+				condition.Message = err.Error()
+			}
+			return condition
+		}
+	}
+
+	condition.Status = scscv1.ConditionTrue
+	condition.Reason = scscv1.ReasonTargetFound
+	condition.Message = fmt.Sprintf("%d targets are found", len(targets))
+	return condition
+}
+
+// HpaCondition returns HPAReady condition, checking the HPA referred by spec.hpaRef exists
+func HpaCondition(cl client.Client, scsc *scscv1.ScheduledScaler) scscv1.Condition {
+	condition := scscv1.Condition{
+		Type:               scscv1.ConditionHPAReady,
+		Status:             scscv1.ConditionTrue,
+		ObservedGeneration: scsc.Generation,
+		Reason:             scscv1.ReasonHPAManaged,
+		Message:            "HPAs are created by range schedules",
+	}
+	if scsc.Spec.HpaRef == nil {
+		return condition
+	}
+
+	hpa, err := k8s.GetHpa(cl, scsc.Spec.HpaRef.Name, scsc.Namespace)
+	switch {
+	case err != nil:
+		condition.Status = scscv1.ConditionUnknown
+		condition.Reason = scscv1.ReasonInternalError
+		condition.Message = err.Error()
+	case hpa == nil:
+		condition.Status = scscv1.ConditionFalse
+		condition.Reason = scscv1.ReasonHPANotFound
+		condition.Message = fmt.Sprintf("HPA %s doesn't exist", scsc.Spec.HpaRef.Name)
+	default:
+		condition.Reason = scscv1.ReasonHPAFound
+		condition.Message = fmt.Sprintf("HPA %s is adopted", scsc.Spec.HpaRef.Name)
+	}
+	return condition
+}
+
+// ReadyCondition returns Ready condition which is true only when every given condition is true
+func ReadyCondition(generation int64, conditions ...scscv1.Condition) scscv1.Condition {
+	for _, condition := range conditions {
+		if condition.Status != scscv1.ConditionTrue {
+			return scscv1.Condition{
+				Type:               scscv1.ConditionReady,
+				Status:             scscv1.ConditionFalse,
+				ObservedGeneration: generation,
+				Reason:             condition.Reason,
+				Message:            condition.Message,
+			}
+		}
+	}
+
+	return scscv1.Condition{
+		Type:               scscv1.ConditionReady,
+		Status:             scscv1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             scscv1.ReasonReconciled,
+		Message:            "Scheduled Scaler is running",
+	}
+}
+
+// lastScaleCondition returns LastScaleSucceeded condition from the latest run of schedules
+func lastScaleCondition(schedules []scscv1.ScheduleStatus, generation int64) scscv1.Condition {
+	var last *scscv1.ScheduleStatus
+	for i := range schedules {
+		if schedules[i].LastRunTime == nil {
+			continue
+		}
+		if last == nil || schedules[i].LastRunTime.After(last.LastRunTime.Time) {
+			last = &schedules[i]
+		}
+	}
+
+	condition := scscv1.Condition{
+		Type:               scscv1.ConditionLastScaleSucceeded,
+		Status:             scscv1.ConditionUnknown,
+		ObservedGeneration: generation,
+		Reason:             scscv1.ReasonNotScaledYet,
+		Message:            "No schedule has run yet",
+	}
+	switch {
+	case last == nil:
+	case last.LastResult == scscv1.ScheduleResultFailed:
+		condition.Status = scscv1.ConditionFalse
+		condition.Reason = scscv1.ReasonScaleFailed
+		condition.Message = fmt.Sprintf("Schedule %d failed: %s", last.Index, last.LastError)
+	default:
+		condition.Status = scscv1.ConditionTrue
+		condition.Reason = scscv1.ReasonScaleSucceeded
+		condition.Message = fmt.Sprintf("Schedule %d succeeded", last.Index)
+	}
+	if last != nil {
+		condition.LastTransitionTime = *last.LastRunTime
+	}
+	return condition
+}
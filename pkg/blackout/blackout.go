@@ -0,0 +1,114 @@
+package blackout
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+/*
+* Blackout is a calendar of dates when scaling is suppressed.
+* Each date is "2006-01-02", or an inclusive range "2006-01-02/2006-01-03"
+ */
+
+const (
+	dateLayout     = "2006-01-02"
+	rangeSeparator = "/"
+)
+
+// Range is a time range of blackout. End is exclusive
+type Range struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (r Range) Contains(t time.Time) bool {
+	return !t.Before(r.Start) && t.Before(r.End)
+}
+
+// GetDates returns dates of the blackout defined inline and in the referred ConfigMap
+func GetDates(cl client.Client, blackout *scscv1.Blackout, namespace string) ([]string, error) {
+	if blackout == nil {
+		return nil, nil
+	}
+
+	var dates []string
+	for _, date := range blackout.Dates {
+		if date = strings.TrimSpace(date); date != "" {
+			dates = append(dates, date)
+		}
+	}
+
+	if blackout.ConfigMapRef != nil {
+		configMap := &corev1.ConfigMap{}
+		if err := cl.Get(context.TODO(), types.NamespacedName{Name: blackout.ConfigMapRef.Name, Namespace: namespace}, configMap); err != nil {
+			return nil, fmt.Errorf("Getting blackout ConfigMap failed: %v", err)
+		}
+
+		keys := make([]string, 0, len(configMap.Data))
+		for key := range configMap.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		// every value has a date per line. Empty lines and comments are skipped
+		for _, key := range keys {
+			scanner := bufio.NewScanner(strings.NewReader(configMap.Data[key]))
+			for scanner.Scan() {
+				if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+					dates = append(dates, line)
+				}
+			}
+		}
+	}
+
+	return dates, nil
+}
+
+// Parse parses dates into ranges in the location
+func Parse(dates []string, loc *time.Location) ([]Range, error) {
+	ranges := make([]Range, 0, len(dates))
+	for _, date := range dates {
+		start, end := date, date
+		if i := strings.Index(date, rangeSeparator); i >= 0 {
+			start, end = date[:i], date[i+len(rangeSeparator):]
+		}
+
+		startDate, err := time.ParseInLocation(dateLayout, strings.TrimSpace(start), loc)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid blackout date %s: %v", date, err)
+		}
+		endDate, err := time.ParseInLocation(dateLayout, strings.TrimSpace(end), loc)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid blackout date %s: %v", date, err)
+		}
+		if endDate.Before(startDate) {
+			return nil, fmt.Errorf("Invalid blackout date %s: end is before start", date)
+		}
+
+		ranges = append(ranges, Range{
+			Start: startDate,
+			End:   endDate.AddDate(0, 0, 1),
+		})
+	}
+
+	return ranges, nil
+}
+
+// Contains returns whether t is in any of ranges
+func Contains(ranges []Range, t time.Time) bool {
+	for _, r := range ranges {
+		if r.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package blackout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParse(t *testing.T) {
+	tc := map[string]struct {
+		dates    []string
+		expected []Range
+		hasError bool
+	}{
+		"date": {
+			dates: []string{"2026-12-25"},
+			expected: []Range{
+				{
+					Start: time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2026, 12, 26, 0, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		"range": {
+			dates: []string{"2026-11-27/2026-11-30"},
+			expected: []Range{
+				{
+					Start: time.Date(2026, 11, 27, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		"invalid date": {
+			dates:    []string{"12/25"},
+			hasError: true,
+		},
+		"end before start": {
+			dates:    []string{"2026-11-30/2026-11-27"},
+			hasError: true,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// do testing function
+			ranges, err := Parse(c.dates, time.UTC)
+
+			// verify by cases
+			if c.hasError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, ranges)
+			require.True(t, Contains(ranges, c.expected[0].Start))
+			require.False(t, Contains(ranges, c.expected[0].End))
+		})
+	}
+}
+
+func TestGetDates(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(s))
+
+	tc := map[string]struct {
+		blackout  *scscv1.Blackout
+		configMap *corev1.ConfigMap
+		expected  []string
+		hasError  bool
+	}{
+		"no blackout": {},
+		"inline dates": {
+			blackout: &scscv1.Blackout{
+				Dates: []string{"2026-12-25", " 2026-11-27/2026-11-30 "},
+			},
+			expected: []string{"2026-12-25", "2026-11-27/2026-11-30"},
+		},
+		"dates in ConfigMap": {
+			blackout: &scscv1.Blackout{
+				Dates: []string{"2026-12-25"},
+				ConfigMapRef: &corev1.LocalObjectReference{
+					Name: "holidays",
+				},
+			},
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "holidays",
+					Namespace: "test-ns",
+				},
+				Data: map[string]string{
+					"2027": "# new year\n2027-01-01\n",
+					"2026": "2026-10-03\n\n2026-10-09",
+				},
+			},
+			expected: []string{"2026-12-25", "2026-10-03", "2026-10-09", "2027-01-01"},
+		},
+		"ConfigMap doesn't exist": {
+			blackout: &scscv1.Blackout{
+				ConfigMapRef: &corev1.LocalObjectReference{
+					Name: "holidays",
+				},
+			},
+			hasError: true,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			fakeCli := fake.NewFakeClientWithScheme(s)
+			if c.configMap != nil {
+				require.NoError(t, fakeCli.Create(context.Background(), c.configMap))
+			}
+
+			// do testing function
+			dates, err := GetDates(fakeCli, c.blackout, "test-ns")
+
+			// verify by cases
+			if c.hasError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, dates)
+		})
+	}
+}
@@ -1,40 +1,139 @@
 package cron
 
 import (
+	"fmt"
+	"sync"
 	"time"
 
 	robfigCron "github.com/robfig/cron"
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/oneoff"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/apimanager"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/blackout"
 	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/scaler"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+var logger = logf.Log.WithName("cron")
+
+// TimeZoneError is returned when the time zone of cron can't be loaded
+type TimeZoneError struct {
+	TimeZone string
+	Err      error
+}
+
+func (e *TimeZoneError) Error() string {
+	return fmt.Sprintf("Loading time zone %s failed: %v", e.TimeZone, e.Err)
+}
+
+// Cron runs scalers of schedule entries. It's safe for concurrent use
 type Cron interface {
 	Push(scaler.Scaler)
+	SetDefault(scaler.Scaler)
+	SetBlackout([]string)
+	SetStatusHandler(func([]scscv1.ScheduleStatus, *metav1.Time))
+	SetHistory([]scscv1.ScheduleStatus)
 	Start() error
 	Stop()
+	Sync() error
 }
 
 type CronImpl struct {
-	timeZone     string
-	internalCron *robfigCron.Cron
-	scalers      []scaler.Scaler
+	timeZone         string
+	startingDeadline *time.Duration
+	internalCron     *robfigCron.Cron
+	scalers          []scaler.Scaler
+	schedules        []scscv1.Schedule
+	times            []scheduleTimes
+	defaultScaler    scaler.Scaler
+	defaultSchedule  scscv1.Schedule
+	blackoutDates    []string
+	blackouts        []blackout.Range
+	lastApplied      time.Time
+	lastRun          scaler.Scaler
+	statuses         []scscv1.ScheduleStatus
+	history          []scscv1.ScheduleStatus
+	statusHandler    func([]scscv1.ScheduleStatus, *metav1.Time)
+	// stopped is set by Stop, because jobs which have already fired keep running after the internal cron is stopped
+	stopped bool
+	// mutex guards every field, because jobs of the internal cron run in their own goroutines
+	mutex sync.Mutex
 }
 
-func NewCron(timeZone string) Cron {
+// NewCron creates cron in the time zone. Missed schedules older than startingDeadlineSeconds aren't caught up on start
+func NewCron(timeZone string, startingDeadlineSeconds *int64) Cron {
+	var startingDeadline *time.Duration
+	if startingDeadlineSeconds != nil {
+		deadline := time.Duration(*startingDeadlineSeconds) * time.Second
+		startingDeadline = &deadline
+	}
+
 	return &CronImpl{
-		timeZone: timeZone,
-		scalers:  make([]scaler.Scaler, 0),
+		timeZone:         timeZone,
+		startingDeadline: startingDeadline,
+		scalers:          make([]scaler.Scaler, 0),
 	}
 }
 
 func (c *CronImpl) Push(scaler scaler.Scaler) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
 	c.scalers = append(c.scalers, scaler)
 }
 
+// SetDefault sets the scaler which is run when no time window is active
+func (c *CronImpl) SetDefault(scaler scaler.Scaler) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.defaultScaler = scaler
+}
+
+// SetBlackout sets dates when scaling is suppressed
+func (c *CronImpl) SetBlackout(dates []string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.blackoutDates = dates
+}
+
+// SetStatusHandler sets the handler which is called with states of schedule entries and the earliest next run time,
+// when the cron is started and whenever a schedule is due
+func (c *CronImpl) SetStatusHandler(handler func([]scscv1.ScheduleStatus, *metav1.Time)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.statusHandler = handler
+}
+
+// SetHistory sets states of schedule entries persisted by previous crons.
+// Their last runs are kept in the status, and a missed schedule which has been applied already isn't replayed on start
+func (c *CronImpl) SetHistory(history []scscv1.ScheduleStatus) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.history = history
+}
+
+// Start builds and starts the cron. Scheduled runs can't begin before the missed schedule is caught up.
+// A stopped cron isn't started again
 func (c *CronImpl) Start() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.stopped {
+		return nil
+	}
+
 	if err := c.init(); err != nil {
 		return err
 	}
 
+	now := time.Now().In(c.internalCron.Location())
+	c.catchUp(now)
+	c.reportStatus(now)
 	c.internalCron.Start()
 	return nil
 }
@@ -49,18 +148,254 @@ func (c *CronImpl) init() error {
 	} else {
 		tz, err := time.LoadLocation(c.timeZone)
 		if err != nil {
-			return err
+			return &TimeZoneError{TimeZone: c.timeZone, Err: err}
 		}
 
 		c.internalCron = robfigCron.NewWithLocation(tz)
 	}
 
-	for _, scaler := range c.scalers {
-		c.internalCron.AddJob(scaler.Schedule().Runat, scaler)
+	blackouts, err := blackout.Parse(c.blackoutDates, c.internalCron.Location())
+	if err != nil {
+		return err
+	}
+	c.blackouts = blackouts
+	// the desired state is applied when a blackout ends
+	for _, r := range c.blackouts {
+		c.internalCron.Schedule(oneoff.Schedule{At: r.End}, robfigCron.FuncJob(c.apply))
+	}
+
+	c.schedules = make([]scscv1.Schedule, 0, len(c.scalers))
+	c.times = make([]scheduleTimes, 0, len(c.scalers))
+	c.statuses = make([]scscv1.ScheduleStatus, 0, len(c.scalers)+1)
+	for i, scaler := range c.scalers {
+		schedule := scaler.Schedule()
+		times, err := parseScheduleTimes(schedule)
+		if err != nil {
+			return err
+		}
+		c.schedules = append(c.schedules, schedule)
+		c.times = append(c.times, times)
+		c.statuses = append(c.statuses, apimanager.WithPreviousRun(scscv1.ScheduleStatus{Index: int32(i), Name: schedule.Name}, c.history))
+
+		// every schedule time only triggers to apply the desired state, so that the result doesn't depend on firing order
+		for _, schedule := range times.schedules() {
+			c.internalCron.Schedule(schedule, robfigCron.FuncJob(c.apply))
+		}
+	}
+	if c.defaultScaler != nil {
+		c.defaultSchedule = c.defaultScaler.Schedule()
+		c.statuses = append(c.statuses, apimanager.WithPreviousRun(scscv1.ScheduleStatus{Index: -1, Name: c.defaultSchedule.Name}, c.history))
 	}
 	return nil
 }
 
 func (c *CronImpl) Stop() {
-	c.internalCron.Stop()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.stopped = true
+	if c.internalCron != nil {
+		c.internalCron.Stop()
+	}
+}
+
+// Sync repairs objects managed by the scaler of the last run, e.g. HPAs edited or deleted by hand
+func (c *CronImpl) Sync() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.lastRun == nil {
+		return nil
+	}
+	return c.lastRun.Sync()
+}
+
+// apply runs the scaler desired at now
+func (c *CronImpl) apply() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.stopped {
+		return
+	}
+
+	// schedules due at the same time trigger only one run
+	now := time.Now().In(c.internalCron.Location())
+	if now.Truncate(time.Second).Equal(c.lastApplied) {
+		return
+	}
+	c.lastApplied = now.Truncate(time.Second)
+
+	defer c.reportStatus(now)
+	if blackout.Contains(c.blackouts, now) {
+		logger.Info("scaling is suppressed by blackout", "time", now)
+		return
+	}
+
+	if desired, since := c.desiredScaler(now); desired != nil {
+		c.run(desired, now, since)
+	}
+}
+
+// catchUp runs the scaler desired at now, so that targets don't wait for the next schedule
+// after the operator is restarted or the spec is changed. It's called with the lock held
+func (c *CronImpl) catchUp(now time.Time) {
+	desired, since := c.desiredScaler(now)
+	if desired == nil {
+		return
+	}
+
+	// objects of the applied scaler are repaired without scaling targets, e.g. HPAs deleted when the cron was rebuilt.
+	// It's done whatever the deadline and blackout are, because they only suppress changes
+	if c.applied(desired, since) {
+		logger.Info("missed schedule has been applied already", "schedule", desired.Schedule().Name, "since", since)
+		c.lastRun = desired
+		if err := desired.Sync(); err != nil {
+			logger.Error(err, "Couldn't repair objects of the applied schedule", "schedule", desired.Schedule().Name)
+		}
+		return
+	}
+
+	if (c.startingDeadline != nil && *c.startingDeadline <= 0) || blackout.Contains(c.blackouts, now) {
+		return
+	}
+
+	if c.startingDeadline != nil && now.Sub(since) > *c.startingDeadline {
+		return
+	}
+
+	c.run(desired, now, since)
+}
+
+// applied returns whether the last run of the scaler succeeded to apply the same state which is desired since the time
+func (c *CronImpl) applied(s scaler.Scaler, since time.Time) bool {
+	status, schedule := c.statusOf(s)
+	if status == nil || status.LastResult != scscv1.ScheduleResultSucceeded {
+		return false
+	}
+
+	if since.IsZero() {
+		// the default scaler is desired since no time window has ended
+		if status.LastRunTime == nil {
+			return false
+		}
+	} else if status.LastScheduleTime == nil || status.LastScheduleTime.Time.Before(since.Truncate(time.Second)) {
+		return false
+	}
+
+	return equalInt32(status.Replicas, schedule.Replicas) &&
+		equalInt32(status.MinReplicas, schedule.MinReplicas) &&
+		equalInt32(status.MaxReplicas, schedule.MaxReplicas) &&
+		status.HpaSpecHash == hpaSpecHash(schedule)
+}
+
+// run runs the scaler desired since the time, and records the result in the status of its schedule entry
+func (c *CronImpl) run(s scaler.Scaler, now, since time.Time) {
+	if c.stopped {
+		return
+	}
+
+	err := s.Run()
+	c.lastRun = s
+
+	status, schedule := c.statusOf(s)
+	if status == nil {
+		return
+	}
+	lastRunTime := metav1.NewTime(now.Truncate(time.Second))
+	status.LastRunTime = &lastRunTime
+	status.LastScheduleTime = toMetaTime(since.Truncate(time.Second))
+	status.LastResult = scscv1.ScheduleResultSucceeded
+	status.LastError = ""
+	if err != nil {
+		status.LastResult = scscv1.ScheduleResultFailed
+		status.LastError = err.Error()
+	}
+	status.Replicas = schedule.Replicas
+	status.MinReplicas = schedule.MinReplicas
+	status.MaxReplicas = schedule.MaxReplicas
+	status.HpaSpecHash = hpaSpecHash(schedule)
+}
+
+// statusOf returns the status and the schedule of the schedule entry of the scaler
+func (c *CronImpl) statusOf(s scaler.Scaler) (*scscv1.ScheduleStatus, scscv1.Schedule) {
+	if s == c.defaultScaler {
+		return &c.statuses[len(c.statuses)-1], c.defaultSchedule
+	}
+
+	for i := range c.scalers {
+		if c.scalers[i] == s {
+			return &c.statuses[i], c.schedules[i]
+		}
+	}
+	return nil, scscv1.Schedule{}
+}
+
+// reportStatus updates next run times of schedule entries, and notifies the status handler of them
+func (c *CronImpl) reportStatus(now time.Time) {
+	var earliest, windowEnd time.Time
+	for i := range c.scalers {
+		next := c.times[i].nextRunTime(now)
+		c.statuses[i].NextRunTime = toMetaTime(next)
+		earliest = earlier(earliest, next)
+
+		if c.times[i].isWindow() {
+			windowEnd = earlier(windowEnd, c.times[i].end.Next(now))
+		}
+	}
+	if c.defaultScaler != nil {
+		c.statuses[len(c.statuses)-1].NextRunTime = toMetaTime(windowEnd)
+	}
+
+	if c.statusHandler == nil {
+		return
+	}
+
+	statuses := make([]scscv1.ScheduleStatus, 0, len(c.statuses))
+	for _, status := range c.statuses {
+		statuses = append(statuses, *status.DeepCopy())
+	}
+	c.statusHandler(statuses, toMetaTime(earliest))
+}
+
+// desiredScaler returns the scaler whose state is desired at now, and the time since when it's desired.
+// An active time window takes precedence. Otherwise, the latest of point schedules and ends of windows decides,
+// and the default scaler is desired after a window ends
+func (c *CronImpl) desiredScaler(now time.Time) (scaler.Scaler, time.Time) {
+	var active, point scaler.Scaler
+	var activePriority, pointPriority int32
+	activeSince, pointSince, windowEnd := time.Time{}, time.Time{}, time.Time{}
+	for i, s := range c.scalers {
+		times := c.times[i]
+		priority := c.schedules[i].Priority
+		if !times.isWindow() {
+			// among schedules due at the same time, the higher priority and then the later in the list wins
+			last, ok := lastScheduleTime(times.runat, now)
+			if ok && (point == nil || last.After(pointSince) || (last.Equal(pointSince) && priority >= pointPriority)) {
+				point, pointSince, pointPriority = s, last, priority
+			}
+			continue
+		}
+
+		start, started := lastScheduleTime(times.start, now)
+		end, ended := lastScheduleTime(times.end, now)
+		// a window starting when the previous one ends is active, e.g. when the duration is as long as the period of start
+		if started && (!ended || !start.Before(end)) {
+			// among overlapping windows, the higher priority and then the later started wins
+			if active == nil || priority > activePriority || (priority == activePriority && !start.Before(activeSince)) {
+				active, activeSince, activePriority = s, start, priority
+			}
+		} else if ended && end.After(windowEnd) {
+			windowEnd = end
+		}
+	}
+
+	switch {
+	case active != nil:
+		return active, activeSince
+	case c.defaultScaler != nil && (point == nil || windowEnd.After(pointSince)):
+		return c.defaultScaler, windowEnd
+	default:
+		return point, pointSince
+	}
 }
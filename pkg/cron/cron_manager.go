@@ -1,52 +1,273 @@
 package cron
 
 import (
+	"context"
+	goerrors "errors"
 	"fmt"
+	"sync"
+	"time"
 
 	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
 	"github.com/tmax-cloud/scheduled-scaler-operator/internal/k8s"
 	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/apimanager"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/blackout"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/metrics"
 	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/scaler"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/keymutex"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// rebuildBackoff is the interval of retrying crons which couldn't be built on handover
+var rebuildBackoff = wait.Backoff{Duration: time.Second, Factor: 2, Steps: 8, Cap: 2 * time.Minute}
+
+// ErrStandby is returned when crons are updated before the operator becomes the leader. The update needs to be retried,
+// because the cron manager may be starting
+var ErrStandby = goerrors.New("Cron manager is in standby")
+
+// CronManager holds crons of ScheduledScalers. It's a runnable of the manager which needs leader election,
+// so that only the leader runs crons
 type CronManager interface {
 	UpdateCron(*scscv1.ScheduledScaler) error
 	RemoveCron(*scscv1.ScheduledScaler) error
+	SyncCron(*scscv1.ScheduledScaler) error
+	UpdateCalendar(namespace, name string) error
+	UpdateBlackout(namespace, name string) error
+	RefersBlackout(namespace, name string) bool
+	Start(<-chan struct{}) error
+	NeedLeaderElection() bool
 }
 
 type CronManagerImpl struct {
 	client.Client
-	scheduleCron map[string]Cron
+	scaleClient      k8s.ScaleClient
+	recorder         record.EventRecorder
+	scheduleCron     map[string]Cron
+	scheduledScalers map[string]*scscv1.ScheduledScaler
+	// blackoutConfigMaps has names of ConfigMaps of blackout dates referred by ScheduledScalers, including ones from calendars
+	blackoutConfigMaps map[string]string
+	// mutex guards the maps and standby. It's never held during API calls
+	mutex sync.Mutex
+	// cronMutex serializes updates of the cron of each ScheduledScaler, which call the API server
+	cronMutex keymutex.KeyMutex
+	// standby is true until the operator becomes the leader. No cron is built in standby
+	standby bool
 }
 
-func NewCronManager(cl client.Client) CronManager {
-	return &CronManagerImpl{
-		Client:       cl,
-		scheduleCron: make(map[string]Cron),
+// NewCronManager creates cron manager. Events of scaling are emitted by the recorder
+// Actual replicas of targets are collected as metrics from the cache whenever they're scraped
+func NewCronManager(cl client.Client, scaleCl k8s.ScaleClient, recorder record.EventRecorder) CronManager {
+	m := &CronManagerImpl{
+		Client:             cl,
+		scaleClient:        scaleCl,
+		recorder:           recorder,
+		scheduleCron:       make(map[string]Cron),
+		scheduledScalers:   make(map[string]*scscv1.ScheduledScaler),
+		blackoutConfigMaps: make(map[string]string),
+		cronMutex:          keymutex.NewHashed(0),
+		standby:            true,
+	}
+	if err := metrics.RegisterReplicasLister(m); err != nil {
+		logger.Error(err, "Couldn't register metrics of actual replicas")
 	}
+	return m
 }
 
 func (m *CronManagerImpl) UpdateCron(scheduledScaler *scscv1.ScheduledScaler) error {
 	key := apimanager.GetNamespacedName(*scheduledScaler)
+	m.cronMutex.LockKey(key)
+	defer m.cronMutex.UnlockKey(key)
+
+	return m.updateCron(scheduledScaler)
+}
+
+// Start builds crons of every ScheduledScaler from the API server when the operator becomes the leader,
+// and stops them when the leadership is lost. Schedules which became due during the handover are caught up
+// within startingDeadlineSeconds when crons are started
+func (m *CronManagerImpl) Start(stop <-chan struct{}) error {
+	m.mutex.Lock()
+	m.standby = false
+	m.mutex.Unlock()
+	failed, err := m.rebuild()
+	if err != nil {
+		logger.Error(err, "Couldn't rebuild crons")
+	}
+
+	// crons which couldn't be built are retried, because nothing else may trigger building them
+	for backoff := rebuildBackoff; len(failed) > 0; {
+		select {
+		case <-stop:
+			failed = nil
+		case <-time.After(backoff.Step()):
+			failed = m.retryRebuild(failed)
+		}
+	}
+
+	<-stop
+
+	m.mutex.Lock()
+	m.standby = true
+	crons := make([]Cron, 0, len(m.scheduleCron))
+	for key, c := range m.scheduleCron {
+		crons = append(crons, c)
+		delete(m.scheduleCron, key)
+	}
+	metrics.ActiveCrons.Set(float64(len(m.scheduleCron)))
+	m.mutex.Unlock()
+
+	// stopping waits for running jobs, so it's done without the lock
+	for _, c := range crons {
+		c.Stop()
+	}
+	logger.Info("Every cron is stopped")
+	return nil
+}
+
+// NeedLeaderElection makes the manager start the cron manager only in the leader
+func (m *CronManagerImpl) NeedLeaderElection() bool {
+	return true
+}
+
+// rebuild builds crons of every ScheduledScaler whatever its phase, because the reconciler may have failed to build them in standby.
+// ScheduledScalers being deleted and invalid ones are left to the reconciler. It returns ScheduledScalers whose crons couldn't be built
+func (m *CronManagerImpl) rebuild() ([]types.NamespacedName, error) {
+	scscList := &scscv1.ScheduledScalerList{}
+	if err := m.List(context.Background(), scscList); err != nil {
+		return nil, fmt.Errorf("Couldn't list ScheduledScalers: %v", err)
+	}
+
+	failed := []types.NamespacedName{}
+	errs := []error{}
+	for i := range scscList.Items {
+		scsc := &scscList.Items[i]
+		if !rebuildable(scsc) {
+			continue
+		}
+		if err := m.UpdateCron(scsc); err != nil {
+			failed = append(failed, types.NamespacedName{Name: scsc.Name, Namespace: scsc.Namespace})
+			errs = append(errs, fmt.Errorf("Couldn't rebuild cron of %s/%s: %v", scsc.Namespace, scsc.Name, err))
+		}
+	}
+	m.mutex.Lock()
+	logger.Info("Crons are rebuilt", "count", len(m.scheduleCron))
+	m.mutex.Unlock()
+
+	return failed, utilerrors.NewAggregate(errs)
+}
+
+// retryRebuild builds crons which couldn't be built, from the latest ScheduledScalers. It returns ones which failed again.
+// Crons built by the reconciler meanwhile are left
+func (m *CronManagerImpl) retryRebuild(keys []types.NamespacedName) []types.NamespacedName {
+	failed := []types.NamespacedName{}
+	for _, key := range keys {
+		scsc := &scscv1.ScheduledScaler{}
+		if err := m.Get(context.Background(), key, scsc); err != nil {
+			if !errors.IsNotFound(err) {
+				logger.Error(err, "Couldn't get ScheduledScaler to rebuild its cron", "scheduledScaler", key)
+				failed = append(failed, key)
+			}
+			continue
+		}
+		if !rebuildable(scsc) {
+			continue
+		}
+
+		m.mutex.Lock()
+		_, exist := m.scheduleCron[apimanager.GetNamespacedName(*scsc)]
+		m.mutex.Unlock()
+		if exist {
+			continue
+		}
+		if err := m.UpdateCron(scsc); err != nil {
+			logger.Error(err, "Couldn't rebuild cron", "scheduledScaler", key)
+			failed = append(failed, key)
+		}
+	}
+	return failed
+}
+
+// rebuildable returns whether the cron of the ScheduledScaler is built by the cron manager
+func rebuildable(scsc *scscv1.ScheduledScaler) bool {
+	return scsc.DeletionTimestamp.IsZero() && len(apimanager.Validate(scsc)) == 0
+}
+
+// updateCron rebuilds the cron of the ScheduledScaler. The caller must hold the key lock of the ScheduledScaler
+func (m *CronManagerImpl) updateCron(scheduledScaler *scscv1.ScheduledScaler) error {
+	m.mutex.Lock()
+	standby := m.standby
+	m.mutex.Unlock()
+	if standby {
+		// crons are built from the API server when the operator becomes the leader
+		return ErrStandby
+	}
+
+	// the ScheduledScaler is recorded before resolving its calendar, so that the cron is rebuilt when the calendar is created later
+	key := apimanager.GetNamespacedName(*scheduledScaler)
+	m.mutex.Lock()
+	m.scheduledScalers[key] = scheduledScaler.DeepCopy()
+	m.mutex.Unlock()
+
+	// calendar and blackout are resolved before stopping previous cron,
+	// so that previous cron keeps running when the calendar or the blackout ConfigMap is broken
+	resolved, err := apimanager.ResolveCalendar(m.Client, scheduledScaler)
+	if err != nil {
+		return err
+	}
+	if scheduledScaler.Spec.CalendarRef != nil {
+		if errs := apimanager.Validate(resolved); len(errs) > 0 {
+			return fmt.Errorf("Schedules merged with calendar %s are invalid: %v", scheduledScaler.Spec.CalendarRef.Name, errs.ToAggregate())
+		}
+	}
+
+	// the blackout ConfigMap is recorded before reading it, so that the cron is rebuilt when the ConfigMap is created later
+	m.mutex.Lock()
+	if resolved.Spec.Blackout != nil && resolved.Spec.Blackout.ConfigMapRef != nil {
+		m.blackoutConfigMaps[key] = resolved.Spec.Blackout.ConfigMapRef.Name
+	} else {
+		delete(m.blackoutConfigMaps, key)
+	}
+	m.mutex.Unlock()
+
+	blackoutDates, err := blackout.GetDates(m.Client, resolved.Spec.Blackout, resolved.Namespace)
+	if err != nil {
+		return err
+	}
+
+	// previous cron is removed with stopping it, so that SyncCron rebuilds the cron when updating it fails from here
+	m.mutex.Lock()
 	previousCron, exist := m.scheduleCron[key]
+	delete(m.scheduleCron, key)
+	metrics.ActiveCrons.Set(float64(len(m.scheduleCron)))
+	m.mutex.Unlock()
 	if exist {
 		previousCron.Stop()
 	}
 
-	if err := k8s.DeleteHpa(m.Client, k8s.GetHpaName(scheduledScaler.Name), scheduledScaler.Namespace); err != nil {
+	if _, err := k8s.DeleteOwnedHpas(m.Client, scheduledScaler.Name, scheduledScaler.Namespace); err != nil {
 		return fmt.Errorf("Couldn't delete previous hpa during update cron by %v", err)
 	}
 
-	tz := "none"
-	if scheduledScaler.Spec.TimeZone != "" {
-		tz = scheduledScaler.Spec.TimeZone
+	// restore HPAs which are not referred anymore
+	adopted := []string{}
+	if scheduledScaler.Spec.HpaRef != nil {
+		adopted = append(adopted, scheduledScaler.Spec.HpaRef.Name)
+	}
+	if err := k8s.RestoreAdoptedHpas(m.Client, scheduledScaler.Name, scheduledScaler.Namespace, adopted...); err != nil {
+		return fmt.Errorf("Couldn't restore previous hpa during update cron by %v", err)
 	}
-	newCron := NewCron(tz)
-	m.scheduleCron[key] = newCron
 
-	for _, schedule := range scheduledScaler.Spec.Schedule {
-		scalerImpl, err := scaler.New(m.Client, scheduledScaler.Name, scheduledScaler.Namespace, scheduledScaler.Spec.Target.Name, schedule)
+	tz := "none"
+	if resolved.Spec.TimeZone != "" {
+		tz = resolved.Spec.TimeZone
+	}
+	newCron := NewCron(tz, resolved.Spec.StartingDeadlineSeconds)
+	for i, schedule := range resolved.Spec.Schedule {
+		scalerImpl, err := scaler.New(m.Client, m.scaleClient, m.recorder, *resolved, withSpecMetrics(resolved, schedule), int32(i))
 		if err != nil {
 			return err
 		}
@@ -54,7 +275,51 @@ func (m *CronManagerImpl) UpdateCron(scheduledScaler *scscv1.ScheduledScaler) er
 		newCron.Push(scalerImpl)
 	}
 
+	if resolved.Spec.Default != nil {
+		defaultScaler, err := scaler.New(m.Client, m.scaleClient, m.recorder, *resolved, withSpecMetrics(resolved, *resolved.Spec.Default), -1)
+		if err != nil {
+			return err
+		}
+
+		newCron.SetDefault(defaultScaler)
+	}
+
+	newCron.SetBlackout(blackoutDates)
+
+	// last runs in the status decide which missed schedules are replayed, e.g. after the operator is restarted
+	newCron.SetHistory(scheduledScaler.Status.Schedules)
+
+	namespacedName := types.NamespacedName{Name: scheduledScaler.Name, Namespace: scheduledScaler.Namespace}
+	newCron.SetStatusHandler(func(schedules []scscv1.ScheduleStatus, nextRunTime *metav1.Time) {
+		metrics.SetNextRunTime(namespacedName.Namespace, namespacedName.Name, nextRunTime)
+		// one-off schedules expire while the cron is running
+		if err := apimanager.UpdateScheduleStatus(m.Client, namespacedName, schedules, nextRunTime, expiredSchedules(resolved, time.Now())); err != nil {
+			logger.Error(err, "Couldn't update schedule status", "scheduledScaler", namespacedName)
+		}
+	})
+
+	m.mutex.Lock()
+	if m.standby {
+		// the leadership is lost while the cron is built, and every cron has been stopped
+		m.mutex.Unlock()
+		return ErrStandby
+	}
+	m.scheduleCron[key] = newCron
+	metrics.ActiveCrons.Set(float64(len(m.scheduleCron)))
+	m.mutex.Unlock()
+
+	// the cron stopped by losing the leadership isn't started
 	if err := newCron.Start(); err != nil {
+		m.mutex.Lock()
+		if m.scheduleCron[key] == newCron {
+			delete(m.scheduleCron, key)
+		}
+		metrics.ActiveCrons.Set(float64(len(m.scheduleCron)))
+		m.mutex.Unlock()
+		return err
+	}
+
+	if err := apimanager.UpdateCalendarStatus(m.Client, scheduledScaler, blackoutDates, expiredSchedules(resolved, time.Now())); err != nil {
 		return err
 	}
 
@@ -63,16 +328,171 @@ func (m *CronManagerImpl) UpdateCron(scheduledScaler *scscv1.ScheduledScaler) er
 
 func (m *CronManagerImpl) RemoveCron(scsc *scscv1.ScheduledScaler) error {
 	key := apimanager.GetNamespacedName(*scsc)
-	targetCron, ok := m.scheduleCron[key]
-	if !ok {
-		return nil
-	}
+	m.cronMutex.LockKey(key)
+	defer m.cronMutex.UnlockKey(key)
 
-	targetCron.Stop()
+	m.mutex.Lock()
+	targetCron, exist := m.scheduleCron[key]
 	delete(m.scheduleCron, key)
-	if err := k8s.DeleteHpa(m.Client, k8s.GetHpaName(scsc.Name), scsc.Namespace); err != nil {
+	delete(m.scheduledScalers, key)
+	delete(m.blackoutConfigMaps, key)
+	metrics.ActiveCrons.Set(float64(len(m.scheduleCron)))
+	m.mutex.Unlock()
+	if exist {
+		targetCron.Stop()
+	}
+	metrics.Delete(scsc.Namespace, scsc.Name)
+
+	// HPAs are cleaned even if cron doesn't exist, e.g. after the operator is restarted
+	if _, err := k8s.DeleteOwnedHpas(m.Client, scsc.Name, scsc.Namespace); err != nil {
 		return err
 	}
 
+	if err := k8s.RestoreAdoptedHpas(m.Client, scsc.Name, scsc.Namespace); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SyncCron repairs objects managed by the cron of the ScheduledScaler, which drifted from its last run.
+// The cron is built when it doesn't exist in the leader, e.g. building it failed on handover. It does nothing in standby
+func (m *CronManagerImpl) SyncCron(scsc *scscv1.ScheduledScaler) error {
+	m.mutex.Lock()
+	c, exist := m.scheduleCron[apimanager.GetNamespacedName(*scsc)]
+	standby := m.standby
+	m.mutex.Unlock()
+
+	if standby {
+		return nil
+	}
+	if !exist {
+		logger.Info("Cron doesn't exist, so it's built", "scheduledScaler", scsc.Name, "namespace", scsc.Namespace)
+		return m.UpdateCron(scsc)
+	}
+	return c.Sync()
+}
+
+// UpdateCalendar rebuilds crons of every ScheduledScaler which refers the calendar.
+// They're rebuilt from the API server, so that last runs in their status aren't replayed
+func (m *CronManagerImpl) UpdateCalendar(namespace, name string) error {
+	m.mutex.Lock()
+	scscs := []*scscv1.ScheduledScaler{}
+	for _, scsc := range m.scheduledScalers {
+		if scsc.Namespace == namespace && scsc.Spec.CalendarRef != nil && scsc.Spec.CalendarRef.Name == name {
+			scscs = append(scscs, scsc)
+		}
+	}
+	m.mutex.Unlock()
+
+	return m.updateLatestCrons(scscs)
+}
+
+// UpdateBlackout rebuilds crons of every ScheduledScaler whose blackout refers the ConfigMap, from the API server
+func (m *CronManagerImpl) UpdateBlackout(namespace, name string) error {
+	m.mutex.Lock()
+	scscs := []*scscv1.ScheduledScaler{}
+	for key, configMap := range m.blackoutConfigMaps {
+		if scsc := m.scheduledScalers[key]; scsc.Namespace == namespace && configMap == name {
+			scscs = append(scscs, scsc)
+		}
+	}
+	m.mutex.Unlock()
+
+	return m.updateLatestCrons(scscs)
+}
+
+// RefersBlackout returns whether any ScheduledScaler refers the ConfigMap as its blackout dates
+func (m *CronManagerImpl) RefersBlackout(namespace, name string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for key, configMap := range m.blackoutConfigMaps {
+		if configMap == name && m.scheduledScalers[key].Namespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// updateLatestCrons rebuilds crons of the ScheduledScalers one by one, holding only the key lock of each
+func (m *CronManagerImpl) updateLatestCrons(scscs []*scscv1.ScheduledScaler) error {
+	errs := []error{}
+	for _, scsc := range scscs {
+		if err := m.updateLatestCron(scsc); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// updateLatestCron rebuilds the cron of the ScheduledScaler read again from the API server.
+// The copy held by the cron manager has the status when the cron was built, so its last runs are stale
+func (m *CronManagerImpl) updateLatestCron(scsc *scscv1.ScheduledScaler) error {
+	key := apimanager.GetNamespacedName(*scsc)
+	m.cronMutex.LockKey(key)
+	defer m.cronMutex.UnlockKey(key)
+
+	latest := &scscv1.ScheduledScaler{}
+	if err := m.Get(context.Background(), types.NamespacedName{Name: scsc.Name, Namespace: scsc.Namespace}, latest); err != nil {
+		if errors.IsNotFound(err) {
+			// the cron is removed by the reconciler
+			return nil
+		}
+		return fmt.Errorf("Couldn't get %s: %v", scsc.Name, err)
+	}
+
+	if err := m.updateCron(latest); err != nil {
+		return fmt.Errorf("Couldn't update cron of %s: %v", scsc.Name, err)
+	}
 	return nil
 }
+
+// ActualReplicas lists current replicas of targets of every ScheduledScaler. They're read from the cache of the client,
+// because they're listed whenever metrics are scraped. Targets which can't be read are skipped
+func (m *CronManagerImpl) ActualReplicas() []metrics.TargetReplicas {
+	m.mutex.Lock()
+	scscs := make([]*scscv1.ScheduledScaler, 0, len(m.scheduledScalers))
+	for _, scsc := range m.scheduledScalers {
+		scscs = append(scscs, scsc)
+	}
+	m.mutex.Unlock()
+
+	replicas := []metrics.TargetReplicas{}
+	for _, scsc := range scscs {
+		targets, err := k8s.ListTargetReplicas(m.Client, scsc.Spec.Target, scsc.Namespace)
+		if err != nil {
+			logger.Error(err, "Couldn't list targets for metrics", "scheduledScaler", scsc.Name)
+			continue
+		}
+		for _, target := range targets {
+			replicas = append(replicas, metrics.TargetReplicas{
+				Namespace: scsc.Namespace,
+				Name:      scsc.Name,
+				Target:    target.Name,
+				Replicas:  target.Replicas,
+			})
+		}
+	}
+	return replicas
+}
+
+// withSpecMetrics returns the schedule using spec.metrics when it has no metrics
+func withSpecMetrics(scsc *scscv1.ScheduledScaler, schedule scscv1.Schedule) scscv1.Schedule {
+	if len(schedule.Metrics) == 0 {
+		schedule.Metrics = scsc.Spec.Metrics
+	}
+	return schedule
+}
+
+// expiredSchedules returns indexes of one-off schedules which have passed at now
+func expiredSchedules(scsc *scscv1.ScheduledScaler, now time.Time) []int32 {
+	var expired []int32
+	for i, schedule := range scsc.Spec.Schedule {
+		if IsExpired(schedule, now) {
+			expired = append(expired, int32(i))
+		}
+	}
+	return expired
+}
@@ -2,18 +2,31 @@ package cron
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
 	"github.com/tmax-cloud/scheduled-scaler-operator/internal/k8s"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/test"
 	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/apimanager"
 	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/cron/fake"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/metrics"
+	appsv1 "k8s.io/api/apps/v1"
 	autov2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/keymutex"
 	fakeCli "sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -109,8 +122,13 @@ func TestCronManager_UpdateCron(t *testing.T) {
 			fakeClient := fakeCli.NewFakeClientWithScheme(s)
 			key := apimanager.GetNamespacedName(*c.scsc)
 			testCronManager := &CronManagerImpl{
-				Client:       fakeClient,
-				scheduleCron: make(map[string]Cron),
+				Client:             fakeClient,
+				scaleClient:        test.NewFakeScaleClient(),
+				recorder:           &record.FakeRecorder{},
+				scheduleCron:       make(map[string]Cron),
+				scheduledScalers:   make(map[string]*scscv1.ScheduledScaler),
+				blackoutConfigMaps: make(map[string]string),
+				cronMutex:          keymutex.NewHashed(0),
 			}
 
 			previosStop := false
@@ -160,10 +178,12 @@ func TestCronManager_RemoveCron(t *testing.T) {
 	scaledReplica := int32(2)
 	min := int32(1)
 	max := int32(3)
+	originalMin := int32(1)
 	tc := map[string]struct {
-		scsc  *scscv1.ScheduledScaler
-		hpa   *autov2beta2.HorizontalPodAutoscaler
-		exist bool
+		scsc       *scscv1.ScheduledScaler
+		hpa        *autov2beta2.HorizontalPodAutoscaler
+		adoptedHpa *autov2beta2.HorizontalPodAutoscaler
+		exist      bool
 	}{
 		"Cron exists": {
 			scsc: &scscv1.ScheduledScaler{
@@ -216,6 +236,45 @@ func TestCronManager_RemoveCron(t *testing.T) {
 			},
 			exist: true,
 		},
+		"Cron & adopted HPA exists": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					HpaRef: &corev1.LocalObjectReference{
+						Name: "user-hpa",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Replicas: &scaledReplica,
+						},
+					},
+				},
+			},
+			adoptedHpa: &autov2beta2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "user-hpa",
+					Namespace: "test-ns",
+					Annotations: map[string]string{
+						k8s.AdoptedByAnnotation:           "test-scsc",
+						k8s.OriginalMinReplicasAnnotation: "1",
+						k8s.OriginalMaxReplicasAnnotation: "3",
+					},
+				},
+				Spec: autov2beta2.HorizontalPodAutoscalerSpec{
+					MinReplicas: &scaledReplica,
+					MaxReplicas: scaledReplica,
+				},
+			},
+			exist: true,
+		},
 		"Cron doesn't exist": {
 			scsc: &scscv1.ScheduledScaler{
 				ObjectMeta: metav1.ObjectMeta{
@@ -244,8 +303,13 @@ func TestCronManager_RemoveCron(t *testing.T) {
 			fakeClient := fakeCli.NewFakeClientWithScheme(s)
 			key := apimanager.GetNamespacedName(*c.scsc)
 			testCronManager := &CronManagerImpl{
-				Client:       fakeClient,
-				scheduleCron: make(map[string]Cron),
+				Client:             fakeClient,
+				scaleClient:        test.NewFakeScaleClient(),
+				recorder:           &record.FakeRecorder{},
+				scheduleCron:       make(map[string]Cron),
+				scheduledScalers:   make(map[string]*scscv1.ScheduledScaler),
+				blackoutConfigMaps: make(map[string]string),
+				cronMutex:          keymutex.NewHashed(0),
 			}
 
 			previosStop := false
@@ -262,6 +326,9 @@ func TestCronManager_RemoveCron(t *testing.T) {
 			if c.hpa != nil {
 				require.NoError(t, fakeClient.Create(context.Background(), c.hpa))
 			}
+			if c.adoptedHpa != nil {
+				require.NoError(t, fakeClient.Create(context.Background(), c.adoptedHpa))
+			}
 
 			// do testing function
 			err := testCronManager.RemoveCron(c.scsc)
@@ -283,6 +350,1004 @@ func TestCronManager_RemoveCron(t *testing.T) {
 				require.Nil(t, test)
 				require.Nil(t, err)
 			}
+
+			if c.adoptedHpa != nil {
+				// adopted hpa must be restored, not deleted
+				restored, err := k8s.GetHpa(fakeClient, c.adoptedHpa.Name, c.adoptedHpa.Namespace)
+				require.NoError(t, err)
+				require.NotNil(t, restored)
+				require.Equal(t, originalMin, *restored.Spec.MinReplicas)
+				require.Equal(t, int32(3), restored.Spec.MaxReplicas)
+				require.Empty(t, restored.Annotations[k8s.AdoptedByAnnotation])
+			}
+		})
+	}
+}
+
+func TestCronManager_UpdateCronCatchUp(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	replica := int32(1)
+	scaledReplica := int32(2)
+	noCatchUp := int64(0)
+	target := scscv1.SchedulingTarget{
+		Name: "test-deploy",
+	}
+	defaultReplica := int32(3)
+	tc := map[string]struct {
+		startingDeadlineSeconds *int64
+		schedule                scscv1.Schedule
+		defaultSchedule         *scscv1.Schedule
+		expectedReplicas        int32
+	}{
+		"missed schedule is applied": {
+			schedule: scscv1.Schedule{
+				Type:     "fixed",
+				Runat:    "0 0 0 1 1 *",
+				Replicas: &scaledReplica,
+			},
+			expectedReplicas: scaledReplica,
+		},
+		"catching up disabled": {
+			startingDeadlineSeconds: &noCatchUp,
+			schedule: scscv1.Schedule{
+				Type:     "fixed",
+				Runat:    "0 0 0 1 1 *",
+				Replicas: &scaledReplica,
+			},
+			expectedReplicas: replica,
+		},
+		"default is applied out of window": {
+			schedule: scscv1.Schedule{
+				Type:     "fixed",
+				Start:    "0 0 0 1 1 *",
+				Duration: &metav1.Duration{Duration: time.Second},
+				Replicas: &scaledReplica,
+			},
+			defaultSchedule: &scscv1.Schedule{
+				Type:     "fixed",
+				Replicas: &defaultReplica,
+			},
+			expectedReplicas: defaultReplica,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			fakeScale := test.NewFakeScaleClient()
+			fakeScale.Set(target, "test-ns", replica)
+			testCronManager := &CronManagerImpl{
+				Client:             fakeCli.NewFakeClientWithScheme(s),
+				scaleClient:        fakeScale,
+				recorder:           &record.FakeRecorder{},
+				scheduleCron:       make(map[string]Cron),
+				scheduledScalers:   make(map[string]*scscv1.ScheduledScaler),
+				blackoutConfigMaps: make(map[string]string),
+				cronMutex:          keymutex.NewHashed(0),
+			}
+			scsc := &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target:                  target,
+					StartingDeadlineSeconds: c.startingDeadlineSeconds,
+					Schedule:                []scscv1.Schedule{c.schedule},
+					Default:                 c.defaultSchedule,
+				},
+			}
+
+			// do testing function
+			err := testCronManager.UpdateCron(scsc)
+
+			// verify by cases
+			require.NoError(t, err)
+			replicas, err := fakeScale.GetReplicas(target, "test-ns")
+			require.NoError(t, err)
+			require.Equal(t, c.expectedReplicas, replicas)
+			require.NoError(t, testCronManager.RemoveCron(scsc))
 		})
 	}
 }
+
+func TestCronManager_UpdateCronApplied(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	// set test case
+	replica := int32(1)
+	scaledReplica := int32(5)
+	min := int32(2)
+	max := int32(10)
+	target := scscv1.SchedulingTarget{
+		Name: "test-deploy",
+	}
+	fakeClient := fakeCli.NewFakeClientWithScheme(s)
+	fakeScale := test.NewFakeScaleClient()
+	fakeScale.Set(target, "test-ns", replica)
+	testCronManager := &CronManagerImpl{
+		Client:             fakeClient,
+		scaleClient:        fakeScale,
+		recorder:           &record.FakeRecorder{},
+		scheduleCron:       make(map[string]Cron),
+		scheduledScalers:   make(map[string]*scscv1.ScheduledScaler),
+		blackoutConfigMaps: make(map[string]string),
+		cronMutex:          keymutex.NewHashed(0),
+	}
+	scsc := &scscv1.ScheduledScaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-scsc",
+			Namespace: "test-ns",
+		},
+		Spec: scscv1.ScheduledScalerSpec{
+			Target: target,
+			Schedule: []scscv1.Schedule{
+				{
+					Type:        "range",
+					Runat:       "0 0 0 1 1 *",
+					MinReplicas: &min,
+					MaxReplicas: &max,
+				},
+			},
+		},
+	}
+	require.NoError(t, fakeClient.Create(context.Background(), scsc))
+	require.NoError(t, testCronManager.UpdateCron(scsc))
+	defer testCronManager.RemoveCron(scsc)
+	// the HPA scales the target out after the missed schedule is applied
+	fakeScale.Set(target, "test-ns", scaledReplica)
+	latest := &scscv1.ScheduledScaler{}
+	require.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{Name: "test-scsc", Namespace: "test-ns"}, latest))
+
+	// do testing function
+	err := testCronManager.UpdateCron(latest)
+
+	// verify: the applied schedule isn't replayed, but its HPA deleted on rebuild is restored
+	require.NoError(t, err)
+	replicas, err := fakeScale.GetReplicas(target, "test-ns")
+	require.NoError(t, err)
+	require.Equal(t, scaledReplica, replicas)
+	hpa, err := k8s.GetHpa(fakeClient, "test-scsc-hpa", "test-ns")
+	require.NoError(t, err)
+	require.NotNil(t, hpa)
+	require.Equal(t, max, hpa.Spec.MaxReplicas)
+}
+
+func TestCronManager_UpdateCronScheduleStatus(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	replica := int32(1)
+	scaledReplica := int32(2)
+	noCatchUp := int64(0)
+	lastRunTime := metav1.NewTime(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	target := scscv1.SchedulingTarget{
+		Name: "test-deploy",
+	}
+	tc := map[string]struct {
+		startingDeadlineSeconds *int64
+		previous                []scscv1.ScheduleStatus
+		expectedResult          scscv1.ScheduleResult
+		expectedLastRunTime     *metav1.Time
+		expectedLastScale       scscv1.ConditionStatus
+	}{
+		"missed schedule is recorded": {
+			expectedResult:    scscv1.ScheduleResultSucceeded,
+			expectedLastScale: scscv1.ConditionTrue,
+		},
+		"previous run is kept": {
+			startingDeadlineSeconds: &noCatchUp,
+			previous: []scscv1.ScheduleStatus{
+				{
+					Index:       0,
+					Name:        "new-year",
+					LastRunTime: &lastRunTime,
+					LastResult:  scscv1.ScheduleResultFailed,
+				},
+			},
+			expectedResult:      scscv1.ScheduleResultFailed,
+			expectedLastRunTime: &lastRunTime,
+			expectedLastScale:   scscv1.ConditionFalse,
+		},
+		"nothing has run": {
+			startingDeadlineSeconds: &noCatchUp,
+			expectedLastScale:       scscv1.ConditionUnknown,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			fakeClient := fakeCli.NewFakeClientWithScheme(s)
+			fakeScale := test.NewFakeScaleClient()
+			fakeScale.Set(target, "test-ns", replica)
+			testCronManager := &CronManagerImpl{
+				Client:             fakeClient,
+				scaleClient:        fakeScale,
+				recorder:           &record.FakeRecorder{},
+				scheduleCron:       make(map[string]Cron),
+				scheduledScalers:   make(map[string]*scscv1.ScheduledScaler),
+				blackoutConfigMaps: make(map[string]string),
+				cronMutex:          keymutex.NewHashed(0),
+			}
+			scsc := &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target:                  target,
+					StartingDeadlineSeconds: c.startingDeadlineSeconds,
+					Schedule: []scscv1.Schedule{
+						{
+							Name:     "new-year",
+							Type:     "fixed",
+							Runat:    "0 0 0 1 1 *",
+							Replicas: &scaledReplica,
+						},
+					},
+				},
+				Status: scscv1.ScheduledScalerStatus{
+					Schedules: c.previous,
+				},
+			}
+			require.NoError(t, fakeClient.Create(context.Background(), scsc))
+
+			// do testing function
+			err := testCronManager.UpdateCron(scsc)
+
+			// verify by cases
+			require.NoError(t, err)
+			result := &scscv1.ScheduledScaler{}
+			require.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{Name: "test-scsc", Namespace: "test-ns"}, result))
+			require.NotNil(t, result.Status.NextRunTime)
+			require.Len(t, result.Status.Schedules, 1)
+			status := result.Status.Schedules[0]
+			require.Equal(t, "new-year", status.Name)
+			require.NotNil(t, status.NextRunTime)
+			require.True(t, status.NextRunTime.Equal(result.Status.NextRunTime))
+			require.Equal(t, c.expectedResult, status.LastResult)
+			switch {
+			case c.expectedLastRunTime != nil:
+				require.True(t, c.expectedLastRunTime.Equal(status.LastRunTime))
+			case c.expectedResult != "":
+				require.NotNil(t, status.LastRunTime)
+				require.Equal(t, &scaledReplica, status.Replicas)
+			default:
+				require.Nil(t, status.LastRunTime)
+			}
+			lastScale := apimanager.FindCondition(result.Status.Conditions, scscv1.ConditionLastScaleSucceeded)
+			require.NotNil(t, lastScale)
+			require.Equal(t, c.expectedLastScale, lastScale.Status)
+			require.NoError(t, testCronManager.RemoveCron(scsc))
+		})
+	}
+}
+
+func TestCronManager_UpdateCronExpiredSchedules(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	// set test case
+	replica := int32(1)
+	scaledReplica := int32(2)
+	target := scscv1.SchedulingTarget{
+		Name: "test-deploy",
+	}
+	fakeClient := fakeCli.NewFakeClientWithScheme(s)
+	fakeScale := test.NewFakeScaleClient()
+	fakeScale.Set(target, "test-ns", replica)
+	testCronManager := &CronManagerImpl{
+		Client:             fakeClient,
+		scaleClient:        fakeScale,
+		recorder:           &record.FakeRecorder{},
+		scheduleCron:       make(map[string]Cron),
+		scheduledScalers:   make(map[string]*scscv1.ScheduledScaler),
+		blackoutConfigMaps: make(map[string]string),
+		cronMutex:          keymutex.NewHashed(0),
+	}
+	scsc := &scscv1.ScheduledScaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-scsc",
+			Namespace: "test-ns",
+		},
+		Spec: scscv1.ScheduledScalerSpec{
+			Target: target,
+			Schedule: []scscv1.Schedule{
+				{
+					Type:     "fixed",
+					At:       &metav1.Time{Time: time.Now().Add(time.Second)},
+					Replicas: &scaledReplica,
+				},
+			},
+		},
+	}
+	require.NoError(t, fakeClient.Create(context.Background(), scsc))
+
+	// do testing function
+	err := testCronManager.UpdateCron(scsc)
+	defer testCronManager.RemoveCron(scsc)
+
+	// verify: the one-off schedule expires without rebuilding the cron
+	require.NoError(t, err)
+	result := &scscv1.ScheduledScaler{}
+	require.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{Name: "test-scsc", Namespace: "test-ns"}, result))
+	require.Empty(t, result.Status.ExpiredSchedules)
+	require.Eventually(t, func() bool {
+		result := &scscv1.ScheduledScaler{}
+		if err := fakeClient.Get(context.Background(), types.NamespacedName{Name: "test-scsc", Namespace: "test-ns"}, result); err != nil {
+			return false
+		}
+		return reflect.DeepEqual([]int32{0}, result.Status.ExpiredSchedules)
+	}, 3*time.Second, 50*time.Millisecond)
+}
+
+func TestCronManager_UpdateCronCalendar(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+	utilruntime.Must(corev1.AddToScheme(s))
+
+	replica := int32(2)
+	tc := map[string]struct {
+		blackout          *scscv1.Blackout
+		configMap         *corev1.ConfigMap
+		expectedBlackouts []string
+		expectedExpired   []int32
+		hasError          bool
+	}{
+		"inline and ConfigMap blackout": {
+			blackout: &scscv1.Blackout{
+				Dates: []string{"2026-11-27/2026-11-30"},
+				ConfigMapRef: &corev1.LocalObjectReference{
+					Name: "holidays",
+				},
+			},
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "holidays",
+					Namespace: "test-ns",
+				},
+				Data: map[string]string{
+					"dates": "2026-12-25",
+				},
+			},
+			expectedBlackouts: []string{"2026-11-27/2026-11-30", "2026-12-25"},
+			expectedExpired:   []int32{1},
+		},
+		"blackout ConfigMap doesn't exist": {
+			blackout: &scscv1.Blackout{
+				ConfigMapRef: &corev1.LocalObjectReference{
+					Name: "holidays",
+				},
+			},
+			hasError: true,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			fakeClient := fakeCli.NewFakeClientWithScheme(s)
+			testCronManager := &CronManagerImpl{
+				Client:             fakeClient,
+				scaleClient:        test.NewFakeScaleClient(),
+				recorder:           &record.FakeRecorder{},
+				scheduleCron:       make(map[string]Cron),
+				scheduledScalers:   make(map[string]*scscv1.ScheduledScaler),
+				blackoutConfigMaps: make(map[string]string),
+				cronMutex:          keymutex.NewHashed(0),
+			}
+			scsc := &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Blackout: c.blackout,
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							At:       &metav1.Time{Time: time.Now().Add(time.Hour)},
+							Replicas: &replica,
+						},
+						{
+							Type:     "fixed",
+							At:       &metav1.Time{Time: time.Now().Add(-time.Hour)},
+							Replicas: &replica,
+						},
+					},
+				},
+			}
+			require.NoError(t, fakeClient.Create(context.Background(), scsc))
+			if c.configMap != nil {
+				require.NoError(t, fakeClient.Create(context.Background(), c.configMap))
+			}
+
+			// previous cron keeps running when the blackout can't be resolved, so it's never stopped
+			var previous Cron
+			if c.hasError {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+				previous = fake.NewMockCron(ctrl)
+				testCronManager.scheduleCron[apimanager.GetNamespacedName(*scsc)] = previous
+			}
+
+			// do testing function
+			err := testCronManager.UpdateCron(scsc)
+
+			// verify by cases
+			if c.hasError {
+				require.Error(t, err)
+				require.Equal(t, previous, testCronManager.scheduleCron[apimanager.GetNamespacedName(*scsc)])
+				return
+			}
+			require.NoError(t, err)
+			defer testCronManager.RemoveCron(scsc)
+
+			updated := &scscv1.ScheduledScaler{}
+			require.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{Name: "test-scsc", Namespace: "test-ns"}, updated))
+			require.Equal(t, c.expectedBlackouts, updated.Status.Blackouts)
+			require.Equal(t, c.expectedExpired, updated.Status.ExpiredSchedules)
+		})
+	}
+}
+
+func TestCronManager_UpdateCalendar(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	replica := int32(1)
+	calendarReplica := int32(2)
+	changedReplica := int32(4)
+	overrideReplica := int32(3)
+	// replicas scaled by others after the schedule is applied. They're kept unless the schedule is replayed
+	driftedReplica := int32(7)
+	target := scscv1.SchedulingTarget{
+		Name: "test-deploy",
+	}
+	tc := map[string]struct {
+		overrides []scscv1.ScheduleOverride
+		// the calendar doesn't exist when the cron is built
+		createdLater     bool
+		expectedReplicas int32
+		changedReplicas  int32
+	}{
+		"calendar schedule": {
+			expectedReplicas: calendarReplica,
+			changedReplicas:  changedReplica,
+		},
+		"calendar schedule overridden": {
+			overrides: []scscv1.ScheduleOverride{
+				{Name: "new-year", Replicas: &overrideReplica},
+			},
+			expectedReplicas: overrideReplica,
+			changedReplicas:  driftedReplica,
+		},
+		"calendar created later": {
+			createdLater:    true,
+			changedReplicas: changedReplica,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			fakeClient := fakeCli.NewFakeClientWithScheme(s)
+			fakeScale := test.NewFakeScaleClient()
+			fakeScale.Set(target, "test-ns", replica)
+			testCronManager := &CronManagerImpl{
+				Client:             fakeClient,
+				scaleClient:        fakeScale,
+				recorder:           &record.FakeRecorder{},
+				scheduleCron:       make(map[string]Cron),
+				scheduledScalers:   make(map[string]*scscv1.ScheduledScaler),
+				blackoutConfigMaps: make(map[string]string),
+				cronMutex:          keymutex.NewHashed(0),
+			}
+			calendar := &scscv1.ScheduleCalendar{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-calendar",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduleCalendarSpec{
+					Schedule: []scscv1.Schedule{
+						{
+							Name:     "new-year",
+							Type:     "fixed",
+							Runat:    "0 0 0 1 1 *",
+							Replicas: &calendarReplica,
+						},
+					},
+				},
+			}
+			scsc := &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: target,
+					CalendarRef: &scscv1.CalendarReference{
+						Name:      "test-calendar",
+						Overrides: c.overrides,
+					},
+				},
+			}
+			require.NoError(t, fakeClient.Create(context.Background(), scsc))
+			if c.createdLater {
+				require.Error(t, testCronManager.UpdateCron(scsc))
+			} else {
+				require.NoError(t, fakeClient.Create(context.Background(), calendar))
+				require.NoError(t, testCronManager.UpdateCron(scsc))
+				replicas, err := fakeScale.GetReplicas(target, "test-ns")
+				require.NoError(t, err)
+				require.Equal(t, c.expectedReplicas, replicas)
+
+				fakeScale.Set(target, "test-ns", driftedReplica)
+			}
+			defer testCronManager.RemoveCron(scsc)
+
+			// do testing function
+			calendar.Spec.Schedule[0].Replicas = &changedReplica
+			if c.createdLater {
+				require.NoError(t, fakeClient.Create(context.Background(), calendar))
+			} else {
+				require.NoError(t, fakeClient.Update(context.Background(), calendar))
+			}
+			err := testCronManager.UpdateCalendar("test-ns", "test-calendar")
+
+			// verify by cases
+			require.NoError(t, err)
+			replicas, err := fakeScale.GetReplicas(target, "test-ns")
+			require.NoError(t, err)
+			require.Equal(t, c.changedReplicas, replicas)
+		})
+	}
+}
+
+func TestCronManager_UpdateBlackout(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+	utilruntime.Must(corev1.AddToScheme(s))
+
+	replica := int32(2)
+	holidays := &corev1.LocalObjectReference{Name: "holidays"}
+	tc := map[string]struct {
+		blackout         *scscv1.Blackout
+		calendarBlackout *scscv1.Blackout
+		configMap        string
+		// the ConfigMap doesn't exist when the cron is built
+		createdLater      bool
+		expectedBlackouts []string
+	}{
+		"ConfigMap referred by spec": {
+			blackout:          &scscv1.Blackout{ConfigMapRef: holidays},
+			configMap:         "holidays",
+			expectedBlackouts: []string{"2026-12-25", "2026-12-31"},
+		},
+		"ConfigMap referred by calendar": {
+			calendarBlackout:  &scscv1.Blackout{ConfigMapRef: holidays},
+			configMap:         "holidays",
+			expectedBlackouts: []string{"2026-12-25", "2026-12-31"},
+		},
+		"other ConfigMap": {
+			blackout:          &scscv1.Blackout{ConfigMapRef: holidays},
+			configMap:         "others",
+			expectedBlackouts: []string{"2026-12-25"},
+		},
+		"ConfigMap created later": {
+			blackout:          &scscv1.Blackout{ConfigMapRef: holidays},
+			configMap:         "holidays",
+			createdLater:      true,
+			expectedBlackouts: []string{"2026-12-25", "2026-12-31"},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			fakeClient := fakeCli.NewFakeClientWithScheme(s)
+			testCronManager := &CronManagerImpl{
+				Client:             fakeClient,
+				scaleClient:        test.NewFakeScaleClient(),
+				recorder:           &record.FakeRecorder{},
+				scheduleCron:       make(map[string]Cron),
+				scheduledScalers:   make(map[string]*scscv1.ScheduledScaler),
+				blackoutConfigMaps: make(map[string]string),
+				cronMutex:          keymutex.NewHashed(0),
+			}
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "holidays",
+					Namespace: "test-ns",
+				},
+				Data: map[string]string{
+					"dates": "2026-12-25",
+				},
+			}
+			if !c.createdLater {
+				require.NoError(t, fakeClient.Create(context.Background(), configMap))
+			}
+			require.NoError(t, fakeClient.Create(context.Background(), &scscv1.ScheduleCalendar{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-calendar",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduleCalendarSpec{
+					Blackout: c.calendarBlackout,
+				},
+			}))
+			scsc := &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					CalendarRef: &scscv1.CalendarReference{
+						Name: "test-calendar",
+					},
+					Blackout: c.blackout,
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "0 0 0 1 1 *",
+							Replicas: &replica,
+						},
+					},
+				},
+			}
+			require.NoError(t, fakeClient.Create(context.Background(), scsc))
+			if c.createdLater {
+				require.Error(t, testCronManager.UpdateCron(scsc))
+			} else {
+				require.NoError(t, testCronManager.UpdateCron(scsc))
+			}
+			defer testCronManager.RemoveCron(scsc)
+			// only ConfigMaps referred by ScheduledScalers are watched
+			require.Equal(t, c.configMap == "holidays", testCronManager.RefersBlackout("test-ns", c.configMap))
+
+			// do testing function
+			configMap.Data["dates"] = "2026-12-25\n2026-12-31"
+			if c.createdLater {
+				require.NoError(t, fakeClient.Create(context.Background(), configMap))
+			} else {
+				require.NoError(t, fakeClient.Update(context.Background(), configMap))
+			}
+			err := testCronManager.UpdateBlackout("test-ns", c.configMap)
+
+			// verify by cases
+			require.NoError(t, err)
+			updated := &scscv1.ScheduledScaler{}
+			require.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{Name: "test-scsc", Namespace: "test-ns"}, updated))
+			require.Equal(t, c.expectedBlackouts, updated.Status.Blackouts)
+		})
+	}
+}
+
+func TestCronManager_ActualReplicas(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+	utilruntime.Must(appsv1.AddToScheme(s))
+
+	replica := int32(3)
+	scaledReplica := int32(2)
+	noCatchUp := int64(0)
+	tc := map[string]struct {
+		targetExists     bool
+		expectedReplicas []metrics.TargetReplicas
+	}{
+		"target exists": {
+			targetExists: true,
+			expectedReplicas: []metrics.TargetReplicas{
+				{Namespace: "test-ns", Name: "test-scsc", Target: "test-deploy", Replicas: replica},
+			},
+		},
+		"target doesn't exist": {
+			expectedReplicas: []metrics.TargetReplicas{},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			target := scscv1.SchedulingTarget{
+				Name: "test-deploy",
+			}
+			fakeClient := fakeCli.NewFakeClientWithScheme(s)
+			if c.targetExists {
+				require.NoError(t, fakeClient.Create(context.Background(), &appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-deploy",
+						Namespace: "test-ns",
+					},
+					Spec: appsv1.DeploymentSpec{
+						Replicas: &replica,
+					},
+				}))
+			}
+			testCronManager := &CronManagerImpl{
+				Client:             fakeClient,
+				scaleClient:        test.NewFakeScaleClient(),
+				recorder:           &record.FakeRecorder{},
+				scheduleCron:       make(map[string]Cron),
+				scheduledScalers:   make(map[string]*scscv1.ScheduledScaler),
+				blackoutConfigMaps: make(map[string]string),
+				cronMutex:          keymutex.NewHashed(0),
+			}
+			scsc := &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target:                  target,
+					StartingDeadlineSeconds: &noCatchUp,
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "0 0 0 1 1 *",
+							Replicas: &scaledReplica,
+						},
+					},
+				},
+			}
+			require.NoError(t, testCronManager.UpdateCron(scsc))
+			require.Equal(t, float64(1), testutil.ToFloat64(metrics.ActiveCrons))
+
+			// do testing function
+			replicas := testCronManager.ActualReplicas()
+
+			// verify by cases
+			require.Equal(t, c.expectedReplicas, replicas)
+			require.NoError(t, testCronManager.RemoveCron(scsc))
+			require.Equal(t, float64(0), testutil.ToFloat64(metrics.ActiveCrons))
+		})
+	}
+}
+
+func TestCronManager_Start(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	// set test case
+	replica := int32(1)
+	scaledReplica := int32(2)
+	target := scscv1.SchedulingTarget{Name: "test-deploy"}
+	newScsc := func(name string, phase scscv1.Status) *scscv1.ScheduledScaler {
+		return &scscv1.ScheduledScaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test-ns",
+			},
+			Spec: scscv1.ScheduledScalerSpec{
+				Target: target,
+				Schedule: []scscv1.Schedule{
+					{
+						Type:     "fixed",
+						Runat:    "0 0 0 1 1 *",
+						Replicas: &scaledReplica,
+					},
+				},
+			},
+			Status: scscv1.ScheduledScalerStatus{Phase: phase},
+		}
+	}
+	invalid := newScsc("invalid-scsc", scscv1.StatusFailed)
+	invalid.Spec.Schedule[0].Replicas = nil
+	// the calendar is created after the cron manager is started, so building the cron fails at first
+	calendarScsc := newScsc("calendar-scsc", scscv1.StatusRunning)
+	calendarScsc.Spec.CalendarRef = &scscv1.CalendarReference{Name: "late-calendar"}
+	fakeClient := fakeCli.NewFakeClientWithScheme(s, newScsc("running-scsc", scscv1.StatusRunning), newScsc("failed-scsc", scscv1.StatusFailed), invalid, calendarScsc)
+	fakeScale := test.NewFakeScaleClient()
+	fakeScale.Set(target, "test-ns", replica)
+	testCronManager := &CronManagerImpl{
+		Client:             fakeClient,
+		scaleClient:        fakeScale,
+		recorder:           &record.FakeRecorder{},
+		scheduleCron:       make(map[string]Cron),
+		scheduledScalers:   make(map[string]*scscv1.ScheduledScaler),
+		blackoutConfigMaps: make(map[string]string),
+		cronMutex:          keymutex.NewHashed(0),
+		standby:            true,
+	}
+
+	// no cron is built in standby, and the update is retried
+	err := testCronManager.UpdateCron(newScsc("running-scsc", scscv1.StatusRunning))
+	require.True(t, errors.Is(err, ErrStandby))
+	require.Empty(t, testCronManager.scheduleCron)
+
+	// do testing function
+	stop := make(chan struct{})
+	stopped := make(chan error)
+	go func() {
+		stopped <- testCronManager.Start(stop)
+	}()
+
+	// verify by cases
+	// crons of valid ScheduledScalers are rebuilt whatever their phase, and the missed schedule is caught up
+	require.Eventually(t, func() bool {
+		testCronManager.mutex.Lock()
+		defer testCronManager.mutex.Unlock()
+		return len(testCronManager.scheduleCron) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Contains(t, testCronManager.scheduleCron, "test-ns-running-scsc")
+	require.Contains(t, testCronManager.scheduleCron, "test-ns-failed-scsc")
+	current, err := fakeScale.GetReplicas(target, "test-ns")
+	require.NoError(t, err)
+	require.Equal(t, scaledReplica, current)
+
+	// the cron which couldn't be built is retried
+	require.NoError(t, fakeClient.Create(context.Background(), &scscv1.ScheduleCalendar{
+		ObjectMeta: metav1.ObjectMeta{Name: "late-calendar", Namespace: "test-ns"},
+	}))
+	require.Eventually(t, func() bool {
+		testCronManager.mutex.Lock()
+		defer testCronManager.mutex.Unlock()
+		_, exist := testCronManager.scheduleCron["test-ns-calendar-scsc"]
+		return exist
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// every cron is stopped when the leadership is lost
+	close(stop)
+	require.NoError(t, <-stopped)
+	require.Empty(t, testCronManager.scheduleCron)
+	require.True(t, testCronManager.standby)
+}
+
+func TestCronManager_SyncCron(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	replica := int32(2)
+	tc := map[string]struct {
+		standby       bool
+		expectedBuilt bool
+	}{
+		"missing cron is built in the leader": {
+			standby:       false,
+			expectedBuilt: true,
+		},
+		"nothing is built in standby": {
+			standby:       true,
+			expectedBuilt: false,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			scsc := &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{Name: "test-deploy"},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "0 0 0 1 1 *",
+							Replicas: &replica,
+						},
+					},
+				},
+				Status: scscv1.ScheduledScalerStatus{Phase: scscv1.StatusRunning},
+			}
+			testCronManager := &CronManagerImpl{
+				Client:             fakeCli.NewFakeClientWithScheme(s, scsc.DeepCopy()),
+				scaleClient:        test.NewFakeScaleClient(),
+				recorder:           &record.FakeRecorder{},
+				scheduleCron:       make(map[string]Cron),
+				scheduledScalers:   make(map[string]*scscv1.ScheduledScaler),
+				blackoutConfigMaps: make(map[string]string),
+				cronMutex:          keymutex.NewHashed(0),
+				standby:            c.standby,
+			}
+
+			// do testing function
+			err := testCronManager.SyncCron(scsc)
+
+			// verify by cases
+			require.NoError(t, err)
+			_, built := testCronManager.scheduleCron[apimanager.GetNamespacedName(*scsc)]
+			require.Equal(t, c.expectedBuilt, built)
+			if built {
+				testCronManager.RemoveCron(scsc)
+			}
+		})
+	}
+}
+
+func TestCronManager_Concurrent(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	// set test case
+	count := 20
+	replica := int32(1)
+	scaledReplica := int32(2)
+	fakeScale := test.NewFakeScaleClient()
+	scscs := make([]*scscv1.ScheduledScaler, 0, count)
+	for i := 0; i < count; i++ {
+		scsc := &scscv1.ScheduledScaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("test-scsc-%d", i),
+				Namespace: "test-ns",
+			},
+			Spec: scscv1.ScheduledScalerSpec{
+				Target: scscv1.SchedulingTarget{Name: fmt.Sprintf("test-deploy-%d", i)},
+				Schedule: []scscv1.Schedule{
+					{
+						Type:     "fixed",
+						Runat:    "0 0 0 1 1 *",
+						Replicas: &scaledReplica,
+					},
+				},
+			},
+		}
+		fakeScale.Set(scsc.Spec.Target, scsc.Namespace, replica)
+		scscs = append(scscs, scsc)
+	}
+	testCronManager := &CronManagerImpl{
+		Client:             fakeCli.NewFakeClientWithScheme(s),
+		scaleClient:        fakeScale,
+		recorder:           &record.FakeRecorder{},
+		scheduleCron:       make(map[string]Cron),
+		scheduledScalers:   make(map[string]*scscv1.ScheduledScaler),
+		blackoutConfigMaps: make(map[string]string),
+		cronMutex:          keymutex.NewHashed(0),
+	}
+
+	// do testing function
+	var wg sync.WaitGroup
+	errs := make(chan error, count*3)
+	for i, scsc := range scscs {
+		wg.Add(1)
+		go func(i int, scsc *scscv1.ScheduledScaler) {
+			defer wg.Done()
+			errs <- testCronManager.UpdateCron(scsc)
+			errs <- testCronManager.UpdateCalendar(scsc.Namespace, "test-calendar")
+			testCronManager.ActualReplicas()
+			if i%2 == 0 {
+				errs <- testCronManager.RemoveCron(scsc)
+			}
+		}(i, scsc)
+	}
+	wg.Wait()
+	close(errs)
+
+	// verify by cases
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	require.Len(t, testCronManager.scheduleCron, count/2)
+	require.Len(t, testCronManager.scheduledScalers, count/2)
+	for _, scsc := range scscs {
+		replicas, err := fakeScale.GetReplicas(scsc.Spec.Target, scsc.Namespace)
+		require.NoError(t, err)
+		require.Equal(t, scaledReplica, replicas)
+	}
+	for _, scsc := range scscs {
+		require.NoError(t, testCronManager.RemoveCron(scsc))
+	}
+}
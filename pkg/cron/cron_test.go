@@ -1,11 +1,16 @@
 package cron
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
 	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
 	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/scaler/fake"
+	autov2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestCron(t *testing.T) {
@@ -37,7 +42,9 @@ func TestCron(t *testing.T) {
 				}
 			})
 
-			testCron := NewCron(c.timezone)
+			// catching up is disabled to check only a scheduled run
+			noCatchUp := int64(0)
+			testCron := NewCron(c.timezone, &noCatchUp)
 
 			// do testing function
 			testCron.Push(m)
@@ -47,3 +54,551 @@ func TestCron(t *testing.T) {
 		})
 	}
 }
+
+func TestCron_CatchUp(t *testing.T) {
+	noCatchUp := int64(0)
+	minute := int64(60)
+	today := time.Now()
+	aroundToday := today.AddDate(0, 0, -1).Format("2006-01-02") + "/" + today.AddDate(0, 0, 1).Format("2006-01-02")
+	tc := map[string]struct {
+		startingDeadlineSeconds *int64
+		blackout                []string
+		runs                    int
+	}{
+		"no deadline": {
+			runs: 1,
+		},
+		"catching up disabled": {
+			startingDeadlineSeconds: &noCatchUp,
+			runs:                    0,
+		},
+		"deadline passed": {
+			startingDeadlineSeconds: &minute,
+			runs:                    0,
+		},
+		"in blackout": {
+			blackout: []string{aroundToday},
+			runs:     0,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			m := fake.NewMockScaler(ctrl)
+			m.EXPECT().Run().Times(c.runs)
+			m.EXPECT().
+				Schedule().DoAndReturn(func() scscv1.Schedule {
+				return scscv1.Schedule{
+					// every new year, so that it's never run by cron during test
+					Runat: "0 0 0 1 1 *",
+				}
+			}).AnyTimes()
+
+			testCron := NewCron("none", c.startingDeadlineSeconds)
+			testCron.Push(m)
+			testCron.SetBlackout(c.blackout)
+
+			// do testing function
+			err := testCron.Start()
+
+			// verify by cases
+			require.NoError(t, err)
+			testCron.Stop()
+		})
+	}
+}
+
+func TestCron_History(t *testing.T) {
+	replicas, changed := int32(2), int32(3)
+	now := time.Now()
+	newYear := metav1.NewTime(time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.Local))
+	lastNewYear := metav1.NewTime(newYear.AddDate(-1, 0, 0))
+	window := int32(60)
+	behavior := &autov2beta2.HorizontalPodAutoscalerBehavior{
+		ScaleDown: &autov2beta2.HPAScalingRules{StabilizationWindowSeconds: &window},
+	}
+	behaviorHash := hpaSpecHash(scscv1.Schedule{Behavior: behavior})
+	noCatchUp := int64(0)
+	aroundToday := now.AddDate(0, 0, -1).Format("2006-01-02") + "/" + now.AddDate(0, 0, 1).Format("2006-01-02")
+	tc := map[string]struct {
+		history                 []scscv1.ScheduleStatus
+		behavior                *autov2beta2.HorizontalPodAutoscalerBehavior
+		startingDeadlineSeconds *int64
+		blackout                []string
+		// runs is 0 when the schedule has been applied already. Then the scaler is synced instead
+		runs int
+	}{
+		"no history": {
+			runs: 1,
+		},
+		"applied already": {
+			history: []scscv1.ScheduleStatus{
+				{Index: 0, Name: "new-year", LastScheduleTime: &newYear, LastResult: scscv1.ScheduleResultSucceeded, Replicas: &replicas},
+			},
+			runs: 0,
+		},
+		"applied already with catching up disabled": {
+			history: []scscv1.ScheduleStatus{
+				{Index: 0, Name: "new-year", LastScheduleTime: &newYear, LastResult: scscv1.ScheduleResultSucceeded, Replicas: &replicas},
+			},
+			startingDeadlineSeconds: &noCatchUp,
+			runs:                    0,
+		},
+		"applied already in blackout": {
+			history: []scscv1.ScheduleStatus{
+				{Index: 0, Name: "new-year", LastScheduleTime: &newYear, LastResult: scscv1.ScheduleResultSucceeded, Replicas: &replicas},
+			},
+			blackout: []string{aroundToday},
+			runs:     0,
+		},
+		"last run failed": {
+			history: []scscv1.ScheduleStatus{
+				{Index: 0, Name: "new-year", LastScheduleTime: &newYear, LastResult: scscv1.ScheduleResultFailed, Replicas: &replicas},
+			},
+			runs: 1,
+		},
+		"previous schedule applied": {
+			history: []scscv1.ScheduleStatus{
+				{Index: 0, Name: "new-year", LastScheduleTime: &lastNewYear, LastResult: scscv1.ScheduleResultSucceeded, Replicas: &replicas},
+			},
+			runs: 1,
+		},
+		"replicas changed": {
+			history: []scscv1.ScheduleStatus{
+				{Index: 0, Name: "new-year", LastScheduleTime: &newYear, LastResult: scscv1.ScheduleResultSucceeded, Replicas: &changed},
+			},
+			runs: 1,
+		},
+		"behavior applied already": {
+			history: []scscv1.ScheduleStatus{
+				{Index: 0, Name: "new-year", LastScheduleTime: &newYear, LastResult: scscv1.ScheduleResultSucceeded, Replicas: &replicas, HpaSpecHash: behaviorHash},
+			},
+			behavior: behavior,
+			runs:     0,
+		},
+		"behavior changed": {
+			history: []scscv1.ScheduleStatus{
+				{Index: 0, Name: "new-year", LastScheduleTime: &newYear, LastResult: scscv1.ScheduleResultSucceeded, Replicas: &replicas},
+			},
+			behavior: behavior,
+			runs:     1,
+		},
+		"other schedule": {
+			history: []scscv1.ScheduleStatus{
+				{Index: 0, Name: "old-year", LastScheduleTime: &newYear, LastResult: scscv1.ScheduleResultSucceeded, Replicas: &replicas},
+			},
+			runs: 1,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			m := fake.NewMockScaler(ctrl)
+			m.EXPECT().Run().Times(c.runs)
+			m.EXPECT().Sync().Times(1 - c.runs)
+			m.EXPECT().Schedule().Return(scscv1.Schedule{
+				Name: "new-year",
+				// every new year, so that it's never run by cron during test
+				Runat:    "0 0 0 1 1 *",
+				Replicas: &replicas,
+				Behavior: c.behavior,
+			}).AnyTimes()
+
+			var statuses []scscv1.ScheduleStatus
+			testCron := NewCron("none", c.startingDeadlineSeconds)
+			testCron.Push(m)
+			testCron.SetBlackout(c.blackout)
+			testCron.SetHistory(c.history)
+			testCron.SetStatusHandler(func(s []scscv1.ScheduleStatus, _ *metav1.Time) {
+				statuses = s
+			})
+
+			// do testing function
+			err := testCron.Start()
+			testCron.Stop()
+
+			// verify by cases
+			require.NoError(t, err)
+			require.Len(t, statuses, 1)
+			require.NotNil(t, statuses[0].LastScheduleTime)
+			require.True(t, newYear.Equal(statuses[0].LastScheduleTime))
+			if c.behavior != nil {
+				require.Equal(t, behaviorHash, statuses[0].HpaSpecHash)
+			}
+		})
+	}
+}
+
+func TestCron_Sync(t *testing.T) {
+	noCatchUp := int64(0)
+	tc := map[string]struct {
+		startingDeadlineSeconds *int64
+		syncs                   int
+	}{
+		"scaler of the last run is synced": {
+			syncs: 1,
+		},
+		"nothing is synced before the first run": {
+			startingDeadlineSeconds: &noCatchUp,
+			syncs:                   0,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			m := fake.NewMockScaler(ctrl)
+			m.EXPECT().Run().Times(c.syncs)
+			m.EXPECT().Sync().Times(c.syncs)
+			m.EXPECT().Schedule().Return(scscv1.Schedule{
+				// every new year, so that it's never run by cron during test
+				Runat: "0 0 0 1 1 *",
+			}).AnyTimes()
+
+			testCron := NewCron("none", c.startingDeadlineSeconds)
+			testCron.Push(m)
+			require.NoError(t, testCron.Start())
+			defer testCron.Stop()
+
+			// do testing function
+			err := testCron.Sync()
+
+			// verify by cases
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCron_Stop(t *testing.T) {
+	noCatchUp := int64(0)
+	tc := map[string]struct {
+		startingDeadlineSeconds *int64
+		startAfterStop          bool
+	}{
+		"job fired before stop doesn't run": {
+			startingDeadlineSeconds: &noCatchUp,
+		},
+		"stopped cron isn't started again": {
+			startAfterStop: true,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			m := fake.NewMockScaler(ctrl)
+			m.EXPECT().Run().Times(0)
+			m.EXPECT().Schedule().Return(scscv1.Schedule{
+				// every new year, so that it's never run by cron during test
+				Runat: "0 0 0 1 1 *",
+			}).AnyTimes()
+
+			testCron := NewCron("none", c.startingDeadlineSeconds).(*CronImpl)
+			testCron.Push(m)
+			if !c.startAfterStop {
+				require.NoError(t, testCron.Start())
+			}
+			testCron.Stop()
+
+			// do testing function
+			var err error
+			if c.startAfterStop {
+				err = testCron.Start()
+			} else {
+				testCron.apply()
+			}
+
+			// verify by cases
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCron_Status(t *testing.T) {
+	replicas := int32(2)
+	tc := map[string]struct {
+		runErr         error
+		expectedResult scscv1.ScheduleResult
+		expectedError  string
+	}{
+		"succeeded": {
+			expectedResult: scscv1.ScheduleResultSucceeded,
+		},
+		"failed": {
+			runErr:         fmt.Errorf("scaling failed"),
+			expectedResult: scscv1.ScheduleResultFailed,
+			expectedError:  "scaling failed",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			m := fake.NewMockScaler(ctrl)
+			m.EXPECT().Run().Return(c.runErr)
+			m.EXPECT().Schedule().Return(scscv1.Schedule{
+				Name: "new-year",
+				// every new year, so that it's never run by cron during test
+				Runat:    "0 0 0 1 1 *",
+				Replicas: &replicas,
+			}).AnyTimes()
+
+			var statuses []scscv1.ScheduleStatus
+			var nextRunTime *metav1.Time
+			testCron := NewCron("none", nil)
+			testCron.Push(m)
+			testCron.SetStatusHandler(func(s []scscv1.ScheduleStatus, next *metav1.Time) {
+				statuses, nextRunTime = s, next
+			})
+
+			// do testing function
+			err := testCron.Start()
+			testCron.Stop()
+
+			// verify by cases
+			require.NoError(t, err)
+			require.Len(t, statuses, 1)
+			require.Equal(t, int32(0), statuses[0].Index)
+			require.Equal(t, "new-year", statuses[0].Name)
+			require.NotNil(t, statuses[0].LastRunTime)
+			require.NotNil(t, statuses[0].LastScheduleTime)
+			require.Equal(t, time.January, statuses[0].LastScheduleTime.Month())
+			require.Equal(t, c.expectedResult, statuses[0].LastResult)
+			require.Equal(t, c.expectedError, statuses[0].LastError)
+			require.Equal(t, &replicas, statuses[0].Replicas)
+			require.NotNil(t, nextRunTime)
+			require.Equal(t, time.January, nextRunTime.Month())
+			require.Equal(t, nextRunTime, statuses[0].NextRunTime)
+		})
+	}
+}
+
+func TestCron_DesiredScaler(t *testing.T) {
+	// 2021-03-10 is wednesday
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+	tc := map[string]struct {
+		schedules  []scscv1.Schedule
+		hasDefault bool
+		expected   int // index of the desired schedule. -1 is default, and -2 is nothing
+		expectedAt time.Time
+	}{
+		"latest point schedule": {
+			schedules: []scscv1.Schedule{
+				{Runat: "0 0 9 * * *"},
+				{Runat: "0 0 11 * * *"},
+				{Runat: "0 0 18 * * *"},
+			},
+			expected:   1,
+			expectedAt: time.Date(2021, 3, 10, 11, 0, 0, 0, time.UTC),
+		},
+		"active window with end": {
+			schedules: []scscv1.Schedule{
+				{Start: "0 0 9 * * 1-5", End: "0 0 18 * * 1-5"},
+			},
+			hasDefault: true,
+			expected:   0,
+			expectedAt: time.Date(2021, 3, 10, 9, 0, 0, 0, time.UTC),
+		},
+		"active window with duration": {
+			schedules: []scscv1.Schedule{
+				{Start: "0 0 11 * * *", Duration: &metav1.Duration{Duration: 2 * time.Hour}},
+			},
+			hasDefault: true,
+			expected:   0,
+			expectedAt: time.Date(2021, 3, 10, 11, 0, 0, 0, time.UTC),
+		},
+		"window as long as its period": {
+			schedules: []scscv1.Schedule{
+				{Start: "0 0 * * * *", Duration: &metav1.Duration{Duration: time.Hour}},
+			},
+			hasDefault: true,
+			expected:   0,
+			expectedAt: time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC),
+		},
+		"default after window ends": {
+			schedules: []scscv1.Schedule{
+				{Start: "0 0 6 * * *", Duration: &metav1.Duration{Duration: 3 * time.Hour}},
+			},
+			hasDefault: true,
+			expected:   -1,
+			expectedAt: time.Date(2021, 3, 10, 9, 0, 0, 0, time.UTC),
+		},
+		"window takes precedence over later point schedule": {
+			schedules: []scscv1.Schedule{
+				{Start: "0 0 9 * * *", End: "0 0 18 * * *"},
+				{Runat: "0 0 11 * * *"},
+			},
+			expected:   0,
+			expectedAt: time.Date(2021, 3, 10, 9, 0, 0, 0, time.UTC),
+		},
+		"later started window wins": {
+			schedules: []scscv1.Schedule{
+				{Start: "0 0 10 * * *", End: "0 0 18 * * *"},
+				{Start: "0 0 9 * * *", End: "0 0 18 * * *"},
+			},
+			expected:   0,
+			expectedAt: time.Date(2021, 3, 10, 10, 0, 0, 0, time.UTC),
+		},
+		"higher priority window wins": {
+			schedules: []scscv1.Schedule{
+				{Start: "0 0 9 * * *", End: "0 0 18 * * *", Priority: 1},
+				{Start: "0 0 10 * * *", End: "0 0 18 * * *"},
+			},
+			expected:   0,
+			expectedAt: time.Date(2021, 3, 10, 9, 0, 0, 0, time.UTC),
+		},
+		"higher priority wins among point schedules due at the same time": {
+			schedules: []scscv1.Schedule{
+				{Runat: "0 0 11 * * *", Priority: 1},
+				{Runat: "0 0 11 * * *"},
+			},
+			expected:   0,
+			expectedAt: time.Date(2021, 3, 10, 11, 0, 0, 0, time.UTC),
+		},
+		"one-off point schedule": {
+			schedules: []scscv1.Schedule{
+				{Runat: "0 0 9 * * *"},
+				{At: &metav1.Time{Time: time.Date(2021, 3, 10, 10, 0, 0, 0, time.UTC)}},
+			},
+			expected:   1,
+			expectedAt: time.Date(2021, 3, 10, 10, 0, 0, 0, time.UTC),
+		},
+		"active one-off window": {
+			schedules: []scscv1.Schedule{
+				{
+					At:    &metav1.Time{Time: time.Date(2021, 3, 9, 0, 0, 0, 0, time.UTC)},
+					Until: &metav1.Time{Time: time.Date(2021, 3, 11, 0, 0, 0, 0, time.UTC)},
+				},
+			},
+			hasDefault: true,
+			expected:   0,
+			expectedAt: time.Date(2021, 3, 9, 0, 0, 0, 0, time.UTC),
+		},
+		"default after one-off window expires": {
+			schedules: []scscv1.Schedule{
+				{
+					At:       &metav1.Time{Time: time.Date(2021, 3, 9, 0, 0, 0, 0, time.UTC)},
+					Duration: &metav1.Duration{Duration: 24 * time.Hour},
+				},
+			},
+			hasDefault: true,
+			expected:   -1,
+			expectedAt: time.Date(2021, 3, 10, 0, 0, 0, 0, time.UTC),
+		},
+		"point schedule after window ends": {
+			schedules: []scscv1.Schedule{
+				{Start: "0 0 6 * * *", End: "0 0 9 * * *"},
+				{Runat: "0 0 10 * * *"},
+			},
+			hasDefault: true,
+			expected:   1,
+			expectedAt: time.Date(2021, 3, 10, 10, 0, 0, 0, time.UTC),
+		},
+		"window not started without default": {
+			schedules: []scscv1.Schedule{
+				{Start: "0 0 9 * * 6", End: "0 0 18 * * 6"},
+			},
+			expected: -2,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			testCron := NewCron("none", nil).(*CronImpl)
+			scalers := make([]*fake.MockScaler, 0, len(c.schedules))
+			for _, schedule := range c.schedules {
+				m := fake.NewMockScaler(ctrl)
+				m.EXPECT().Schedule().Return(schedule).AnyTimes()
+				testCron.Push(m)
+				scalers = append(scalers, m)
+			}
+			defaultScaler := fake.NewMockScaler(ctrl)
+			defaultScaler.EXPECT().Schedule().Return(scscv1.Schedule{}).AnyTimes()
+			if c.hasDefault {
+				testCron.SetDefault(defaultScaler)
+			}
+			require.NoError(t, testCron.init())
+
+			// do testing function
+			desired, since := testCron.desiredScaler(now)
+
+			// verify by cases
+			switch c.expected {
+			case -2:
+				require.Nil(t, desired)
+			case -1:
+				require.Same(t, defaultScaler, desired)
+				require.True(t, c.expectedAt.Equal(since))
+			default:
+				require.Same(t, scalers[c.expected], desired)
+				require.True(t, c.expectedAt.Equal(since))
+			}
+		})
+	}
+}
+
+func TestIsExpired(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+	tc := map[string]struct {
+		schedule scscv1.Schedule
+		expired  bool
+	}{
+		"cron schedule": {
+			schedule: scscv1.Schedule{Runat: "0 0 9 * * *"},
+			expired:  false,
+		},
+		"passed one-off point": {
+			schedule: scscv1.Schedule{At: &metav1.Time{Time: now.Add(-time.Hour)}},
+			expired:  true,
+		},
+		"upcoming one-off point": {
+			schedule: scscv1.Schedule{At: &metav1.Time{Time: now.Add(time.Hour)}},
+			expired:  false,
+		},
+		"active one-off window": {
+			schedule: scscv1.Schedule{
+				At:    &metav1.Time{Time: now.Add(-time.Hour)},
+				Until: &metav1.Time{Time: now.Add(time.Hour)},
+			},
+			expired: false,
+		},
+		"ended one-off window": {
+			schedule: scscv1.Schedule{
+				At:       &metav1.Time{Time: now.Add(-2 * time.Hour)},
+				Duration: &metav1.Duration{Duration: time.Hour},
+			},
+			expired: true,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// do testing function
+			expired := IsExpired(c.schedule, now)
+
+			// verify by cases
+			require.Equal(t, c.expired, expired)
+		})
+	}
+}
@@ -8,7 +8,9 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	v1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
 	scaler "github.com/tmax-cloud/scheduled-scaler-operator/pkg/scaler"
+	v10 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // MockCron is a mock of Cron interface.
@@ -46,6 +48,54 @@ func (mr *MockCronMockRecorder) Push(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockCron)(nil).Push), arg0)
 }
 
+// SetBlackout mocks base method.
+func (m *MockCron) SetBlackout(arg0 []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetBlackout", arg0)
+}
+
+// SetBlackout indicates an expected call of SetBlackout.
+func (mr *MockCronMockRecorder) SetBlackout(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBlackout", reflect.TypeOf((*MockCron)(nil).SetBlackout), arg0)
+}
+
+// SetDefault mocks base method.
+func (m *MockCron) SetDefault(arg0 scaler.Scaler) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetDefault", arg0)
+}
+
+// SetDefault indicates an expected call of SetDefault.
+func (mr *MockCronMockRecorder) SetDefault(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDefault", reflect.TypeOf((*MockCron)(nil).SetDefault), arg0)
+}
+
+// SetHistory mocks base method.
+func (m *MockCron) SetHistory(arg0 []v1.ScheduleStatus) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetHistory", arg0)
+}
+
+// SetHistory indicates an expected call of SetHistory.
+func (mr *MockCronMockRecorder) SetHistory(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHistory", reflect.TypeOf((*MockCron)(nil).SetHistory), arg0)
+}
+
+// SetStatusHandler mocks base method.
+func (m *MockCron) SetStatusHandler(arg0 func([]v1.ScheduleStatus, *v10.Time)) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetStatusHandler", arg0)
+}
+
+// SetStatusHandler indicates an expected call of SetStatusHandler.
+func (mr *MockCronMockRecorder) SetStatusHandler(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatusHandler", reflect.TypeOf((*MockCron)(nil).SetStatusHandler), arg0)
+}
+
 // Start mocks base method.
 func (m *MockCron) Start() error {
 	m.ctrl.T.Helper()
@@ -71,3 +121,17 @@ func (mr *MockCronMockRecorder) Stop() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockCron)(nil).Stop))
 }
+
+// Sync mocks base method.
+func (m *MockCron) Sync() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockCronMockRecorder) Sync() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockCron)(nil).Sync))
+}
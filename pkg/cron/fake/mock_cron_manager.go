@@ -34,6 +34,34 @@ func (m *MockCronManager) EXPECT() *MockCronManagerMockRecorder {
 	return m.recorder
 }
 
+// NeedLeaderElection mocks base method.
+func (m *MockCronManager) NeedLeaderElection() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NeedLeaderElection")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// NeedLeaderElection indicates an expected call of NeedLeaderElection.
+func (mr *MockCronManagerMockRecorder) NeedLeaderElection() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NeedLeaderElection", reflect.TypeOf((*MockCronManager)(nil).NeedLeaderElection))
+}
+
+// RefersBlackout mocks base method.
+func (m *MockCronManager) RefersBlackout(namespace, name string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefersBlackout", namespace, name)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// RefersBlackout indicates an expected call of RefersBlackout.
+func (mr *MockCronManagerMockRecorder) RefersBlackout(namespace, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefersBlackout", reflect.TypeOf((*MockCronManager)(nil).RefersBlackout), namespace, name)
+}
+
 // RemoveCron mocks base method.
 func (m *MockCronManager) RemoveCron(arg0 *v1.ScheduledScaler) error {
 	m.ctrl.T.Helper()
@@ -48,6 +76,62 @@ func (mr *MockCronManagerMockRecorder) RemoveCron(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCron", reflect.TypeOf((*MockCronManager)(nil).RemoveCron), arg0)
 }
 
+// Start mocks base method.
+func (m *MockCronManager) Start(arg0 <-chan struct{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockCronManagerMockRecorder) Start(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockCronManager)(nil).Start), arg0)
+}
+
+// SyncCron mocks base method.
+func (m *MockCronManager) SyncCron(arg0 *v1.ScheduledScaler) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncCron", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncCron indicates an expected call of SyncCron.
+func (mr *MockCronManagerMockRecorder) SyncCron(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncCron", reflect.TypeOf((*MockCronManager)(nil).SyncCron), arg0)
+}
+
+// UpdateBlackout mocks base method.
+func (m *MockCronManager) UpdateBlackout(namespace, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBlackout", namespace, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBlackout indicates an expected call of UpdateBlackout.
+func (mr *MockCronManagerMockRecorder) UpdateBlackout(namespace, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBlackout", reflect.TypeOf((*MockCronManager)(nil).UpdateBlackout), namespace, name)
+}
+
+// UpdateCalendar mocks base method.
+func (m *MockCronManager) UpdateCalendar(namespace, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCalendar", namespace, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCalendar indicates an expected call of UpdateCalendar.
+func (mr *MockCronManagerMockRecorder) UpdateCalendar(namespace, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCalendar", reflect.TypeOf((*MockCronManager)(nil).UpdateCalendar), namespace, name)
+}
+
 // UpdateCron mocks base method.
 func (m *MockCronManager) UpdateCron(arg0 *v1.ScheduledScaler) error {
 	m.ctrl.T.Helper()
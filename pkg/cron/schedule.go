@@ -0,0 +1,174 @@
+package cron
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	robfigCron "github.com/robfig/cron"
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/oneoff"
+	autov2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// lookbacks are windows to find the previous schedule time. Shorter windows are tried first
+// because robfig/cron only computes the next time, so the window is iterated from its start
+var lookbacks = []time.Duration{
+	time.Minute,
+	time.Hour,
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+	31 * 24 * time.Hour,
+	366 * 24 * time.Hour,
+	4 * 366 * 24 * time.Hour,
+}
+
+// scheduleTimes has cron schedules of a Schedule. runat is set for a point schedule, and start and end are set for a time window
+type scheduleTimes struct {
+	runat robfigCron.Schedule
+	start robfigCron.Schedule
+	end   robfigCron.Schedule
+}
+
+func parseScheduleTimes(schedule scscv1.Schedule) (scheduleTimes, error) {
+	if schedule.At != nil {
+		at := oneoff.Schedule{At: schedule.At.Time}
+		switch {
+		case schedule.Until != nil:
+			return scheduleTimes{start: at, end: oneoff.Schedule{At: schedule.Until.Time}}, nil
+		case schedule.Duration != nil:
+			return scheduleTimes{start: at, end: shiftedSchedule{schedule: at, offset: schedule.Duration.Duration}}, nil
+		default:
+			return scheduleTimes{runat: at}, nil
+		}
+	}
+
+	if schedule.Start == "" {
+		runat, err := robfigCron.Parse(schedule.Runat)
+		return scheduleTimes{runat: runat}, err
+	}
+
+	start, err := robfigCron.Parse(schedule.Start)
+	if err != nil {
+		return scheduleTimes{}, err
+	}
+
+	if schedule.Duration != nil {
+		return scheduleTimes{start: start, end: shiftedSchedule{schedule: start, offset: schedule.Duration.Duration}}, nil
+	}
+
+	end, err := robfigCron.Parse(schedule.End)
+	return scheduleTimes{start: start, end: end}, err
+}
+
+func (t scheduleTimes) isWindow() bool {
+	return t.start != nil
+}
+
+// schedules returns every cron schedule when the desired state can be changed
+func (t scheduleTimes) schedules() []robfigCron.Schedule {
+	if t.isWindow() {
+		return []robfigCron.Schedule{t.start, t.end}
+	}
+	return []robfigCron.Schedule{t.runat}
+}
+
+// nextRunTime returns when the schedule is applied next after now. It's zero when the schedule is never applied again
+func (t scheduleTimes) nextRunTime(now time.Time) time.Time {
+	if t.isWindow() {
+		return t.start.Next(now)
+	}
+	return t.runat.Next(now)
+}
+
+// earlier returns the earlier of non-zero times
+func earlier(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+func toMetaTime(t time.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
+	}
+	mt := metav1.NewTime(t)
+	return &mt
+}
+
+// IsExpired returns whether the one-off schedule has passed at now, so that it's never due again
+func IsExpired(schedule scscv1.Schedule, now time.Time) bool {
+	if schedule.At == nil {
+		return false
+	}
+
+	times, err := parseScheduleTimes(schedule)
+	if err != nil {
+		return false
+	}
+
+	last := times.runat
+	if times.isWindow() {
+		last = times.end
+	}
+	return last.Next(now).IsZero()
+}
+
+// shiftedSchedule is due offset after every time of the schedule. It's the end of a window with duration
+type shiftedSchedule struct {
+	schedule robfigCron.Schedule
+	offset   time.Duration
+}
+
+func (s shiftedSchedule) Next(t time.Time) time.Time {
+	next := s.schedule.Next(t.Add(-s.offset))
+	if next.IsZero() {
+		return next
+	}
+	return next.Add(s.offset)
+}
+
+// lastScheduleTime returns the latest time when schedule was due at or before now
+func lastScheduleTime(schedule robfigCron.Schedule, now time.Time) (time.Time, bool) {
+	for _, lookback := range lookbacks {
+		last := time.Time{}
+		for next := schedule.Next(now.Add(-lookback)); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+			last = next
+		}
+
+		if !last.IsZero() {
+			return last, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// hpaSpecHash returns the hash of metrics and behavior of HPAs of the schedule. It's empty when neither is set
+func hpaSpecHash(schedule scscv1.Schedule) string {
+	if len(schedule.Metrics) == 0 && schedule.Behavior == nil {
+		return ""
+	}
+
+	data, err := json.Marshal(struct {
+		Metrics  []autov2beta2.MetricSpec                     `json:"metrics,omitempty"`
+		Behavior *autov2beta2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+	}{schedule.Metrics, schedule.Behavior})
+	if err != nil {
+		return ""
+	}
+
+	hasher := fnv.New32a()
+	hasher.Write(data)
+	return strconv.FormatUint(uint64(hasher.Sum32()), 16)
+}
+
+func equalInt32(a, b *int32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
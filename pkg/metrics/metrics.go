@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Results of scale executions
+const (
+	ResultSucceeded = "succeeded"
+	ResultFailed    = "failed"
+)
+
+var (
+	// ScaleExecutions is the number of scale executions of schedules by result.
+	// Schedules are labeled by their index as in the status, because names of schedules are optional
+	ScaleExecutions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scheduledscaler_scale_executions_total",
+		Help: "Number of scale executions of schedules by result. schedule is the index of the schedule entry, and -1 is spec.default",
+	}, []string{"namespace", "name", "schedule", "result"})
+	// LastSuccessTimestamp is when a schedule of the ScheduledScaler succeeded last
+	LastSuccessTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scheduledscaler_last_success_timestamp_seconds",
+		Help: "Unix time when a schedule of the ScheduledScaler succeeded last",
+	}, []string{"namespace", "name"})
+	// NextRunTimestamp is when a schedule of the ScheduledScaler runs next
+	NextRunTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scheduledscaler_next_run_timestamp_seconds",
+		Help: "Unix time when a schedule of the ScheduledScaler runs next",
+	}, []string{"namespace", "name"})
+	// DesiredMinReplicas and DesiredMaxReplicas are bounds of replicas applied by the last run. They're the same for fixed schedules
+	DesiredMinReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scheduledscaler_desired_min_replicas",
+		Help: "Minimum replicas of targets applied by the last run of the ScheduledScaler",
+	}, []string{"namespace", "name"})
+	DesiredMaxReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scheduledscaler_desired_max_replicas",
+		Help: "Maximum replicas of targets applied by the last run of the ScheduledScaler",
+	}, []string{"namespace", "name"})
+	// ActiveCrons is the number of crons held by the cron manager
+	ActiveCrons = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "scheduledscaler_active_crons",
+		Help: "Number of crons held by the cron manager",
+	})
+
+	actualReplicasDesc = prometheus.NewDesc(
+		"scheduledscaler_actual_replicas",
+		"Current replicas of each target of the ScheduledScaler",
+		[]string{"namespace", "name", "target"}, nil,
+	)
+)
+
+// executions keeps schedules whose executions are counted for each ScheduledScaler, to delete their series
+var executions = struct {
+	sync.Mutex
+	schedules map[string]map[string]bool
+}{schedules: make(map[string]map[string]bool)}
+
+func init() {
+	metrics.Registry.MustRegister(
+		ScaleExecutions,
+		LastSuccessTimestamp,
+		NextRunTimestamp,
+		DesiredMinReplicas,
+		DesiredMaxReplicas,
+		ActiveCrons,
+	)
+}
+
+// RecordExecution counts the execution of the schedule entry at the index, and records the time when it succeeded
+func RecordExecution(namespace, name string, index int32, err error, now time.Time) {
+	schedule := strconv.Itoa(int(index))
+	executions.Lock()
+	key := namespace + "/" + name
+	if executions.schedules[key] == nil {
+		executions.schedules[key] = make(map[string]bool)
+	}
+	executions.schedules[key][schedule] = true
+	executions.Unlock()
+
+	if err != nil {
+		ScaleExecutions.WithLabelValues(namespace, name, schedule, ResultFailed).Inc()
+		return
+	}
+	ScaleExecutions.WithLabelValues(namespace, name, schedule, ResultSucceeded).Inc()
+	LastSuccessTimestamp.WithLabelValues(namespace, name).Set(float64(now.Unix()))
+}
+
+// SetDesiredReplicas records bounds of replicas applied by the run
+func SetDesiredReplicas(namespace, name string, min, max int32) {
+	DesiredMinReplicas.WithLabelValues(namespace, name).Set(float64(min))
+	DesiredMaxReplicas.WithLabelValues(namespace, name).Set(float64(max))
+}
+
+// SetNextRunTime records when a schedule runs next. The series is deleted when no schedule runs again
+func SetNextRunTime(namespace, name string, next *metav1.Time) {
+	if next == nil {
+		NextRunTimestamp.DeleteLabelValues(namespace, name)
+		return
+	}
+	NextRunTimestamp.WithLabelValues(namespace, name).Set(float64(next.Unix()))
+}
+
+// Delete deletes every series of the ScheduledScaler
+func Delete(namespace, name string) {
+	executions.Lock()
+	key := namespace + "/" + name
+	for schedule := range executions.schedules[key] {
+		ScaleExecutions.DeleteLabelValues(namespace, name, schedule, ResultSucceeded)
+		ScaleExecutions.DeleteLabelValues(namespace, name, schedule, ResultFailed)
+	}
+	delete(executions.schedules, key)
+	executions.Unlock()
+
+	LastSuccessTimestamp.DeleteLabelValues(namespace, name)
+	NextRunTimestamp.DeleteLabelValues(namespace, name)
+	DesiredMinReplicas.DeleteLabelValues(namespace, name)
+	DesiredMaxReplicas.DeleteLabelValues(namespace, name)
+}
+
+// TargetReplicas is current replicas of a target of the ScheduledScaler
+type TargetReplicas struct {
+	Namespace string
+	Name      string
+	Target    string
+	Replicas  int32
+}
+
+// ReplicasLister lists current replicas of targets. It's called whenever metrics are scraped,
+// so that drift of targets from the desired replicas is observed without waiting for the next schedule
+type ReplicasLister interface {
+	ActualReplicas() []TargetReplicas
+}
+
+type replicasCollector struct {
+	lister ReplicasLister
+}
+
+// RegisterReplicasLister registers the collector of actual replicas listed by the lister
+func RegisterReplicasLister(lister ReplicasLister) error {
+	return metrics.Registry.Register(&replicasCollector{lister: lister})
+}
+
+func (c *replicasCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- actualReplicasDesc
+}
+
+func (c *replicasCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, r := range c.lister.ActualReplicas() {
+		ch <- prometheus.MustNewConstMetric(actualReplicasDesc, prometheus.GaugeValue, float64(r.Replicas), r.Namespace, r.Name, r.Target)
+	}
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordExecution(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+	tc := map[string]struct {
+		errs              []error
+		expectedSucceeded float64
+		expectedFailed    float64
+		expectedSuccess   float64
+	}{
+		"succeeded": {
+			errs:              []error{nil, nil},
+			expectedSucceeded: 2,
+			expectedSuccess:   float64(now.Unix()),
+		},
+		"failed": {
+			errs:           []error{fmt.Errorf("scaling failed")},
+			expectedFailed: 1,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			defer Delete("test-ns", name)
+
+			// do testing function
+			for _, err := range c.errs {
+				RecordExecution("test-ns", name, 0, err, now)
+			}
+
+			// verify by cases
+			require.Equal(t, c.expectedSucceeded, testutil.ToFloat64(ScaleExecutions.WithLabelValues("test-ns", name, "0", ResultSucceeded)))
+			require.Equal(t, c.expectedFailed, testutil.ToFloat64(ScaleExecutions.WithLabelValues("test-ns", name, "0", ResultFailed)))
+			require.Equal(t, c.expectedSuccess, testutil.ToFloat64(LastSuccessTimestamp.WithLabelValues("test-ns", name)))
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	// set test case
+	RecordExecution("test-ns", "test-scsc", 0, nil, time.Now())
+	SetDesiredReplicas("test-ns", "test-scsc", 1, 3)
+
+	// do testing function
+	Delete("test-ns", "test-scsc")
+
+	// verify by cases
+	for _, collector := range []prometheus.Collector{ScaleExecutions, LastSuccessTimestamp, DesiredMinReplicas, DesiredMaxReplicas} {
+		// no series must be left
+		require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader("")))
+	}
+}
+
+type fakeLister []TargetReplicas
+
+func (l fakeLister) ActualReplicas() []TargetReplicas {
+	return l
+}
+
+func TestReplicasCollector(t *testing.T) {
+	// set test case
+	collector := &replicasCollector{lister: fakeLister{
+		{Namespace: "test-ns", Name: "test-scsc", Target: "worker-1", Replicas: 2},
+		{Namespace: "test-ns", Name: "test-scsc", Target: "worker-2", Replicas: 3},
+	}}
+	expected := `
+# HELP scheduledscaler_actual_replicas Current replicas of each target of the ScheduledScaler
+# TYPE scheduledscaler_actual_replicas gauge
+scheduledscaler_actual_replicas{name="test-scsc",namespace="test-ns",target="worker-1"} 2
+scheduledscaler_actual_replicas{name="test-scsc",namespace="test-ns",target="worker-2"} 3
+`
+
+	// do testing function
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected))
+
+	// verify by cases
+	require.NoError(t, err)
+}
@@ -0,0 +1,42 @@
+package scaler
+
+import (
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/k8s"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/apimanager"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ApplyDeletionPolicy scales targets of the deleted scsc by its deletion policy. It must be called after cron and HPA are removed
+func ApplyDeletionPolicy(cl client.Client, scaleCl k8s.ScaleClient, scsc scscv1.ScheduledScaler) error {
+	policy := scsc.Spec.DeletionPolicy
+	if policy == nil || policy.Type == scscv1.DeletionPolicyKeep {
+		return nil
+	}
+
+	targets, err := k8s.ListTargets(cl, scsc.Spec.Target, scsc.Namespace)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		replicas := policy.Replicas
+		if policy.Type == scscv1.DeletionPolicyRestore {
+			replicas = apimanager.GetOriginalReplicas(&scsc, target.Name)
+			if replicas == nil {
+				logger.Info("original replicas isn't recorded", "target", target.Name)
+				continue
+			}
+		}
+
+		if err := scaleCl.ScaleReplicas(target, scsc.Namespace, replicas); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+	}
+
+	return nil
+}
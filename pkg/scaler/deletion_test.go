@@ -0,0 +1,82 @@
+package scaler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/test"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApplyDeletionPolicy(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+
+	currentReplicas := int32(5)
+	policyReplicas := int32(2)
+	target := scscv1.SchedulingTarget{
+		Name: "test-deploy",
+	}
+
+	tc := map[string]struct {
+		policy           *scscv1.DeletionPolicy
+		originalReplicas []scscv1.TargetReplicas
+		expectedReplicas int32
+	}{
+		"no policy": {
+			expectedReplicas: currentReplicas,
+		},
+		"keep policy": {
+			policy: &scscv1.DeletionPolicy{
+				Type: scscv1.DeletionPolicyKeep,
+			},
+			expectedReplicas: currentReplicas,
+		},
+		"restore policy": {
+			policy: &scscv1.DeletionPolicy{
+				Type: scscv1.DeletionPolicyRestore,
+			},
+			originalReplicas: []scscv1.TargetReplicas{
+				{Name: "test-deploy", Replicas: 3},
+			},
+			expectedReplicas: 3,
+		},
+		"restore policy without record": {
+			policy: &scscv1.DeletionPolicy{
+				Type: scscv1.DeletionPolicyRestore,
+			},
+			expectedReplicas: currentReplicas,
+		},
+		"scale policy": {
+			policy: &scscv1.DeletionPolicy{
+				Type:     scscv1.DeletionPolicyScale,
+				Replicas: &policyReplicas,
+			},
+			expectedReplicas: policyReplicas,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			fakeCli := fake.NewFakeClientWithScheme(s)
+			fakeScale := test.NewFakeScaleClient()
+			fakeScale.Set(target, "test-ns", currentReplicas)
+			scsc := newScheduledScaler(target)
+			scsc.Spec.DeletionPolicy = c.policy
+			scsc.Status.OriginalReplicas = c.originalReplicas
+
+			// do testing function
+			err := ApplyDeletionPolicy(fakeCli, fakeScale, scsc)
+
+			// verify by cases
+			require.NoError(t, err)
+			replicas, err := fakeScale.GetReplicas(target, "test-ns")
+			require.NoError(t, err)
+			require.Equal(t, c.expectedReplicas, replicas)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package scaler
+
+import (
+	"fmt"
+
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Reasons of events emitted by scalers
+const (
+	ReasonScaled         = "Scaled"
+	ReasonScalingFailed  = "ScalingFailed"
+	ReasonTargetNotFound = "TargetNotFound"
+	ReasonHPACreated     = "HPACreated"
+	ReasonHPAUpdated     = "HPAUpdated"
+	ReasonHPADeleted     = "HPADeleted"
+	ReasonHPAFailed      = "HPAFailed"
+	ReasonHPARepaired    = "HPARepaired"
+)
+
+// scheduledScalerRef returns the reference of scsc, which events are emitted on
+func scheduledScalerRef(scsc scscv1.ScheduledScaler) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: scscv1.GroupVersion.String(),
+		Kind:       "ScheduledScaler",
+		Name:       scsc.Name,
+		Namespace:  scsc.Namespace,
+		UID:        scsc.UID,
+	}
+}
+
+func (s *ScalerImpl) targetRef(target scscv1.SchedulingTarget) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: k8s.GetTargetAPIVersion(target),
+		Kind:       k8s.GetTargetKind(target),
+		Name:       target.Name,
+		Namespace:  s.namespace,
+	}
+}
+
+// eventf emits the event on the scheduled scaler
+func (s *ScalerImpl) eventf(eventType, reason, messageFmt string, args ...interface{}) {
+	s.recorder.Eventf(s.scheduledScalerRef, eventType, reason, messageFmt, args...)
+}
+
+// scaledEvent emits the event of the scaled target on both of the scheduled scaler and the target
+func (s *ScalerImpl) scaledEvent(target scscv1.SchedulingTarget, replicas int32) {
+	message := fmt.Sprintf("Scaled %s %s to %d replicas", k8s.GetTargetKind(target), target.Name, replicas)
+	s.recorder.Event(s.scheduledScalerRef, corev1.EventTypeNormal, ReasonScaled, message)
+	s.recorder.Eventf(s.targetRef(target), corev1.EventTypeNormal, ReasonScaled, "%s by ScheduledScaler %s", message, s.scheduledScaler)
+}
+
+// scalingFailedEvent emits the event of the scaling failure. Target not found is emitted only on the scheduled scaler
+func (s *ScalerImpl) scalingFailedEvent(target scscv1.SchedulingTarget, err error) {
+	if errors.IsNotFound(err) {
+		s.eventf(corev1.EventTypeWarning, ReasonTargetNotFound, "%s %s is not found", k8s.GetTargetKind(target), target.Name)
+		return
+	}
+
+	message := fmt.Sprintf("Scaling %s %s failed: %v", k8s.GetTargetKind(target), target.Name, err)
+	s.recorder.Event(s.scheduledScalerRef, corev1.EventTypeWarning, ReasonScalingFailed, message)
+	s.recorder.Eventf(s.targetRef(target), corev1.EventTypeWarning, ReasonScalingFailed, "%s by ScheduledScaler %s", message, s.scheduledScaler)
+}
+
+// hpaDeletedEvents emits events of deleted HPAs
+func (s *ScalerImpl) hpaDeletedEvents(names []string) {
+	for _, name := range names {
+		s.eventf(corev1.EventTypeNormal, ReasonHPADeleted, "Deleted HPA %s", name)
+	}
+}
@@ -35,9 +35,11 @@ func (m *MockScaler) EXPECT() *MockScalerMockRecorder {
 }
 
 // Run mocks base method.
-func (m *MockScaler) Run() {
+func (m *MockScaler) Run() error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Run")
+	ret := m.ctrl.Call(m, "Run")
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // Run indicates an expected call of Run.
@@ -59,3 +61,17 @@ func (mr *MockScalerMockRecorder) Schedule() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Schedule", reflect.TypeOf((*MockScaler)(nil).Schedule))
 }
+
+// Sync mocks base method.
+func (m *MockScaler) Sync() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockScalerMockRecorder) Sync() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockScaler)(nil).Sync))
+}
@@ -1,7 +1,11 @@
 package scaler
 
 import (
+	"fmt"
+
 	"github.com/tmax-cloud/scheduled-scaler-operator/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 )
 
 // FixedScaler is ...
@@ -9,23 +13,65 @@ type FixedScaler struct {
 	ScalerImpl
 }
 
-func (s *FixedScaler) Run() {
+func (s *FixedScaler) Run() error {
+	err := s.scale()
+	s.recordRun(err, *s.schedule.Replicas, *s.schedule.Replicas)
+	return err
+}
+
+func (s *FixedScaler) scale() error {
 	logger.Info("FixedScaler start running")
-	if err := k8s.DeleteHpa(s.cl, k8s.GetHpaName(s.scheduledScaler), s.namespace); err != nil {
-		logger.Error(err, "Cleaning HPA failed in FixedScaler")
-		return
-	}
 	replicas := s.schedule.DeepCopy().Replicas
-	targetDeploy, err := k8s.GetTargetDeployment(s.cl, s.target, s.namespace)
+	if s.hpaRef != "" {
+		// adopted HPA isn't deleted, but paused by pinning its bounds to the fixed replicas
+		if err := k8s.PatchAdoptedHpaReplicas(s.cl, s.scheduledScaler, s.hpaRef, s.namespace, replicas, *replicas); err != nil {
+			logger.Error(err, "Pausing adopted HPA failed in FixedScaler")
+			s.eventf(corev1.EventTypeWarning, ReasonHPAFailed, "Pausing HPA %s failed: %v", s.hpaRef, err)
+			return err
+		}
+		s.eventf(corev1.EventTypeNormal, ReasonHPAUpdated, "Paused HPA %s at %d replicas", s.hpaRef, *replicas)
+	} else {
+		deleted, err := k8s.DeleteOwnedHpas(s.cl, s.scheduledScaler, s.namespace)
+		s.hpaDeletedEvents(deleted)
+		if err != nil {
+			logger.Error(err, "Cleaning HPA failed in FixedScaler")
+			s.eventf(corev1.EventTypeWarning, ReasonHPAFailed, "Cleaning HPA failed: %v", err)
+			return err
+		}
+	}
+
+	targets, err := s.targets()
 	if err != nil {
-		logger.Error(err, "Getting deployment error in FixedScaler")
-		return
+		logger.Error(err, "Getting targets error in FixedScaler")
+		s.eventf(corev1.EventTypeWarning, ReasonTargetNotFound, "Getting targets failed: %v", err)
+		return err
+	} else if len(targets) == 0 {
+		s.eventf(corev1.EventTypeWarning, ReasonTargetNotFound, "No workload matches the selector")
+	}
+
+	// targets are never scaled without their original replicas
+	if err := s.recordOriginalReplicas(); err != nil {
+		logger.Error(err, "Recording original replicas failed in FixedScaler")
+		s.eventf(corev1.EventTypeWarning, ReasonScalingFailed, "Recording original replicas failed: %v", err)
+		return err
 	}
 
-	if err = k8s.ScaleDeploymentReplicas(s.cl, targetDeploy, replicas); err != nil {
-		logger.Error(err, "Patching deployment error in FixedScaler")
-		return
+	errs := []error{}
+	for _, target := range targets {
+		if err := s.scaleCl.ScaleReplicas(target, s.namespace, replicas); err != nil {
+			logger.Error(err, "Scaling target error in FixedScaler", "target", target.Name)
+			s.scalingFailedEvent(target, err)
+			errs = append(errs, fmt.Errorf("Scaling %s failed: %v", target.Name, err))
+			continue
+		}
+		s.scaledEvent(target, *replicas)
 	}
 
 	logger.Info("scaling done")
+	return utilerrors.NewAggregate(errs)
+}
+
+// Sync does nothing, because FixedScaler manages no HPA. Replicas of targets are left to others until the next run
+func (s *FixedScaler) Sync() error {
+	return nil
 }
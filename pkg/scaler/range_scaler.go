@@ -1,7 +1,12 @@
 package scaler
 
 import (
+	"fmt"
+
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
 	"github.com/tmax-cloud/scheduled-scaler-operator/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 )
 
 // RangeScaler is ..
@@ -9,29 +14,119 @@ type RangeScaler struct {
 	ScalerImpl
 }
 
-func (s *RangeScaler) Run() {
+func (s *RangeScaler) Run() error {
+	err := s.scale()
+	s.recordRun(err, *s.schedule.MinReplicas, *s.schedule.MaxReplicas)
+	return err
+}
+
+func (s *RangeScaler) scale() error {
 	logger.Info("RangeScaler start running")
-	targetDeploy, err := k8s.GetTargetDeployment(s.cl, s.target, s.namespace)
+	if s.hpaRef != "" {
+		// adopted HPA keeps its own metrics and behavior, only bounds are patched
+		if err := k8s.PatchAdoptedHpaReplicas(s.cl, s.scheduledScaler, s.hpaRef, s.namespace, s.schedule.MinReplicas, *s.schedule.MaxReplicas); err != nil {
+			logger.Error(err, "Patching adopted HPA failed in RangeScaler")
+			s.eventf(corev1.EventTypeWarning, ReasonHPAFailed, "Patching HPA %s failed: %v", s.hpaRef, err)
+			return err
+		}
+		s.eventf(corev1.EventTypeNormal, ReasonHPAUpdated, "Updated HPA %s to %d-%d replicas", s.hpaRef, *s.schedule.MinReplicas, *s.schedule.MaxReplicas)
+
+		logger.Info("scaling done")
+		return nil
+	}
+
+	targets, err := s.targets()
+	if err != nil {
+		logger.Error(err, "Getting targets error in RangeScaler")
+		s.eventf(corev1.EventTypeWarning, ReasonTargetNotFound, "Getting targets failed: %v", err)
+		return err
+	} else if len(targets) == 0 {
+		s.eventf(corev1.EventTypeWarning, ReasonTargetNotFound, "No workload matches the selector")
+	}
+
+	// targets are never scaled without their original replicas
+	if err := s.recordOriginalReplicas(); err != nil {
+		logger.Error(err, "Recording original replicas failed in RangeScaler")
+		s.eventf(corev1.EventTypeWarning, ReasonScalingFailed, "Recording original replicas failed: %v", err)
+		return err
+	}
+
+	errs := []error{}
+	hpaNames := make([]string, 0, len(targets))
+	for _, target := range targets {
+		hpaName := s.hpaName(target)
+		hpaNames = append(hpaNames, hpaName)
+		if err := s.scaleCl.ScaleReplicas(target, s.namespace, s.schedule.MinReplicas); err != nil {
+			logger.Error(err, "Scaling target error in RangeScaler", "target", target.Name)
+			s.scalingFailedEvent(target, err)
+			errs = append(errs, fmt.Errorf("Scaling %s failed: %v", target.Name, err))
+			continue
+		}
+		s.scaledEvent(target, *s.schedule.MinReplicas)
+
+		created, err := k8s.UpdateHpa(s.cl, s.hpaOptions(target, hpaName))
+		switch {
+		case err != nil:
+			logger.Error(err, "Creating Hpa failed in Range scaler", "target", target.Name)
+			s.eventf(corev1.EventTypeWarning, ReasonHPAFailed, "Updating HPA %s failed: %v", hpaName, err)
+			errs = append(errs, fmt.Errorf("Updating HPA of %s failed: %v", target.Name, err))
+		case created:
+			s.eventf(corev1.EventTypeNormal, ReasonHPACreated, "Created HPA %s with %d-%d replicas", hpaName, *s.schedule.MinReplicas, *s.schedule.MaxReplicas)
+		default:
+			s.eventf(corev1.EventTypeNormal, ReasonHPAUpdated, "Updated HPA %s to %d-%d replicas", hpaName, *s.schedule.MinReplicas, *s.schedule.MaxReplicas)
+		}
+	}
+
+	// clean HPAs of workloads which aren't selected anymore
+	deleted, err := k8s.DeleteOwnedHpas(s.cl, s.scheduledScaler, s.namespace, hpaNames...)
+	s.hpaDeletedEvents(deleted)
 	if err != nil {
-		logger.Error(err, "Getting deployment error in RangeScaler")
-		return
+		logger.Error(err, "Cleaning HPA failed in RangeScaler")
+		s.eventf(corev1.EventTypeWarning, ReasonHPAFailed, "Cleaning HPA failed: %v", err)
+		errs = append(errs, err)
 	}
 
-	if err = k8s.ScaleDeploymentReplicas(s.cl, targetDeploy, s.schedule.MinReplicas); err != nil {
-		logger.Error(err, "Patching deployment error in RangeScaler")
-		return
+	logger.Info("scaling done")
+	return utilerrors.NewAggregate(errs)
+}
+
+// Sync recreates HPAs deleted by hand, and restores HPAs edited by hand. Adopted HPA is left to its owner
+func (s *RangeScaler) Sync() error {
+	if s.hpaRef != "" {
+		return nil
+	}
+
+	targets, err := s.targets()
+	if err != nil {
+		return err
 	}
 
-	if err := k8s.UpdateHpa(s.cl, &k8s.HpaValidationOptions{
+	errs := []error{}
+	for _, target := range targets {
+		hpaName := s.hpaName(target)
+		repaired, err := k8s.SyncHpa(s.cl, s.hpaOptions(target, hpaName))
+		if err != nil {
+			logger.Error(err, "Repairing Hpa failed in Range scaler", "target", target.Name)
+			s.eventf(corev1.EventTypeWarning, ReasonHPAFailed, "Repairing HPA %s failed: %v", hpaName, err)
+			errs = append(errs, fmt.Errorf("Repairing HPA of %s failed: %v", target.Name, err))
+		} else if repaired {
+			s.eventf(corev1.EventTypeNormal, ReasonHPARepaired, "Repaired HPA %s drifted from %d-%d replicas", hpaName, *s.schedule.MinReplicas, *s.schedule.MaxReplicas)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (s *RangeScaler) hpaOptions(target scscv1.SchedulingTarget, hpaName string) *k8s.HpaValidationOptions {
+	return &k8s.HpaValidationOptions{
+		Name:                hpaName,
 		Namespace:           s.namespace,
-		Target:              s.target,
+		Target:              target,
 		ScheduledScalerName: s.scheduledScaler,
 		MinReplicas:         s.schedule.MinReplicas,
 		MaxReplicas:         s.schedule.MaxReplicas,
-	}); err != nil {
-		logger.Error(err, "Creating Hpa failed in Range scaler")
-		return
+		Metrics:             s.schedule.Metrics,
+		Behavior:            s.schedule.Behavior,
+		Owner:               s.owner,
 	}
-
-	logger.Info("scaling done")
 }
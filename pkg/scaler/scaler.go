@@ -1,7 +1,18 @@
 package scaler
 
 import (
+	"context"
+	"time"
+
 	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/k8s"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/apimanager"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -11,29 +22,50 @@ var logger = logf.Log.WithName("scaler")
 // Scaler is ...
 type Scaler interface {
 	Schedule() scscv1.Schedule
-	Run()
+	Run() error
+	// Sync repairs objects managed by the scaler which drifted from its schedule, without scaling targets
+	Sync() error
 }
 
 type ScalerImpl struct {
-	scheduledScaler string
-	target          string
-	namespace       string
-	schedule        scscv1.Schedule
-	cl              client.Client
+	scheduledScaler    string
+	scheduledScalerRef *corev1.ObjectReference
+	owner              *metav1.OwnerReference
+	target             scscv1.SchedulingTarget
+	namespace          string
+	hpaRef             string
+	schedule           scscv1.Schedule
+	cl                 client.Client
+	scaleCl            k8s.ScaleClient
+	recorder           record.EventRecorder
+	// index of the schedule entry as in the status. It's -1 for spec.default
+	index int32
 }
 
 func (s *ScalerImpl) Schedule() scscv1.Schedule {
 	return s.schedule
 }
 
-func New(cl client.Client, name, namespace, targetDeploy string, schedule scscv1.Schedule) (Scaler, error) {
+// New creates the scaler of the schedule entry at the index. Events of scaling are emitted by the recorder
+func New(cl client.Client, scaleCl k8s.ScaleClient, recorder record.EventRecorder, scsc scscv1.ScheduledScaler, schedule scscv1.Schedule, index int32) (Scaler, error) {
 	var scaler Scaler
 	scalerImpl := ScalerImpl{
-		scheduledScaler: name,
-		target:          targetDeploy,
-		namespace:       namespace,
-		schedule:        schedule,
-		cl:              cl,
+		scheduledScaler:    scsc.Name,
+		scheduledScalerRef: scheduledScalerRef(scsc),
+		target:             scsc.Spec.Target,
+		namespace:          scsc.Namespace,
+		schedule:           schedule,
+		index:              index,
+		cl:                 cl,
+		scaleCl:            scaleCl,
+		recorder:           recorder,
+	}
+	if scsc.Spec.HpaRef != nil {
+		scalerImpl.hpaRef = scsc.Spec.HpaRef.Name
+	}
+	// owner reference needs the UID given by the server
+	if scsc.UID != "" {
+		scalerImpl.owner = metav1.NewControllerRef(&scsc, scscv1.GroupVersion.WithKind("ScheduledScaler"))
 	}
 
 	switch schedule.Type {
@@ -49,3 +81,35 @@ func New(cl client.Client, name, namespace, targetDeploy string, schedule scscv1
 
 	return scaler, nil
 }
+
+// recordRun records metrics of the run, with bounds of replicas applied by it
+func (s *ScalerImpl) recordRun(err error, min, max int32) {
+	metrics.RecordExecution(s.namespace, s.scheduledScaler, s.index, err, time.Now())
+	metrics.SetDesiredReplicas(s.namespace, s.scheduledScaler, min, max)
+}
+
+// targets resolves the scaling target into workloads whenever scaler runs, so that workloads selected by label selector are up-to-date
+func (s *ScalerImpl) targets() ([]scscv1.SchedulingTarget, error) {
+	return k8s.ListTargets(s.cl, s.target, s.namespace)
+}
+
+// recordOriginalReplicas records replicas of targets before they're scaled for the first time, e.g. workloads which started
+// matching the selector after the ScheduledScaler was reconciled, so that they're restored by the deletion policy
+func (s *ScalerImpl) recordOriginalReplicas() error {
+	scsc := &scscv1.ScheduledScaler{}
+	if err := s.cl.Get(context.TODO(), types.NamespacedName{Name: s.scheduledScaler, Namespace: s.namespace}, scsc); err != nil {
+		if errors.IsNotFound(err) {
+			// nothing is restored for ScheduledScaler which is deleted already
+			return nil
+		}
+		return err
+	}
+	return apimanager.RecordOriginalReplicas(s.cl, s.scaleCl, scsc)
+}
+
+func (s *ScalerImpl) hpaName(target scscv1.SchedulingTarget) string {
+	if s.target.Selector == nil {
+		return k8s.GetHpaName(s.scheduledScaler)
+	}
+	return k8s.GetTargetHpaName(s.scheduledScaler, target.Name)
+}
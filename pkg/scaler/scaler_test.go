@@ -2,23 +2,27 @@ package scaler
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
 	"github.com/tmax-cloud/scheduled-scaler-operator/internal/k8s"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/test"
 	appsv1 "k8s.io/api/apps/v1"
 	autov2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestScaler_Run(t *testing.T) {
 	s := runtime.NewScheme()
 	utilruntime.Must(scscv1.AddToScheme(s))
-	utilruntime.Must(appsv1.AddToScheme(s))
 	utilruntime.Must(autov2beta2.AddToScheme(s))
 
 	replica := int32(1)
@@ -192,15 +196,16 @@ func TestScaler_Run(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			// set test case
 			fakeCli := fake.NewFakeClientWithScheme(s)
-			require.NoError(t, fakeCli.Create(context.Background(), c.target))
-			testScaler, err := New(fakeCli, c.scsc.Name, c.scsc.Namespace, c.scsc.Spec.Target.Name, c.scsc.Spec.Schedule[0])
+			fakeScale := test.NewFakeScaleClient()
+			fakeScale.Set(c.scsc.Spec.Target, c.target.Namespace, *c.target.Spec.Replicas)
+			testScaler, err := New(fakeCli, fakeScale, &record.FakeRecorder{}, *c.scsc, c.scsc.Spec.Schedule[0], 0)
 			require.NoError(t, err)
 			if c.multiSchedule {
 				if c.types == "fixed" {
 					// in multi schedule, hpa already exists before fixed scaling
 					k8s.UpdateHpa(fakeCli, &k8s.HpaValidationOptions{
 						Namespace:           c.scsc.Namespace,
-						Target:              c.target.Name,
+						Target:              c.scsc.Spec.Target,
 						ScheduledScalerName: c.scsc.Name,
 						MinReplicas:         c.hpa.Spec.MinReplicas,
 						MaxReplicas:         &c.hpa.Spec.MaxReplicas,
@@ -215,13 +220,14 @@ func TestScaler_Run(t *testing.T) {
 			}
 
 			// do testing function
-			testScaler.Run()
+			err = testScaler.Run()
 
 			// verify by cases
+			require.NoError(t, err)
 			if c.types == "fixed" {
 				// when fixed scaling
-				scaled, _ := k8s.GetTargetDeployment(fakeCli, c.target.Name, c.target.Namespace)
-				require.Equal(t, scaledReplica, *(scaled.Spec.Replicas))
+				scaled, _ := fakeScale.GetReplicas(c.scsc.Spec.Target, c.target.Namespace)
+				require.Equal(t, scaledReplica, scaled)
 				if c.multiSchedule {
 					// after fixed scaling, hpa must be deleted
 					hpa, err := k8s.GetHpa(fakeCli, k8s.GetHpaName(c.scsc.Name), c.scsc.Namespace)
@@ -231,8 +237,8 @@ func TestScaler_Run(t *testing.T) {
 			} else {
 				// when range scaling
 				// after range scaling, current replicas must be min replicas at first
-				scaled, _ := k8s.GetTargetDeployment(fakeCli, c.target.Name, c.target.Namespace)
-				require.Equal(t, min, *(scaled.Spec.Replicas))
+				scaled, _ := fakeScale.GetReplicas(c.scsc.Spec.Target, c.target.Namespace)
+				require.Equal(t, min, scaled)
 				// after range scaling, hpa must be created
 				hpa, err := k8s.GetHpa(fakeCli, k8s.GetHpaName(c.scsc.Name), c.scsc.Namespace)
 				require.NoError(t, err)
@@ -244,3 +250,765 @@ func TestScaler_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestScaler_RunWithTargetKind(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	replica := int32(1)
+	scaledReplica := int32(2)
+	min := int32(1)
+	max := int32(3)
+	statefulSet := scscv1.SchedulingTarget{
+		Kind:       "StatefulSet",
+		APIVersion: "apps/v1",
+		Name:       "test-target",
+	}
+	rollout := scscv1.SchedulingTarget{
+		Kind:       "Rollout",
+		APIVersion: "argoproj.io/v1alpha1",
+		Name:       "test-target",
+	}
+
+	tc := map[string]struct {
+		target           scscv1.SchedulingTarget
+		schedule         scscv1.Schedule
+		expectedReplicas int32
+		hpaExists        bool
+	}{
+		"fixed scaling statefulset": {
+			target: statefulSet,
+			schedule: scscv1.Schedule{
+				Type:     "fixed",
+				Runat:    "* * * * *",
+				Replicas: &scaledReplica,
+			},
+			expectedReplicas: scaledReplica,
+		},
+		"range scaling statefulset": {
+			target: statefulSet,
+			schedule: scscv1.Schedule{
+				Type:        "range",
+				Runat:       "* * * * *",
+				MinReplicas: &min,
+				MaxReplicas: &max,
+			},
+			expectedReplicas: min,
+			hpaExists:        true,
+		},
+		"fixed scaling custom resource": {
+			target: rollout,
+			schedule: scscv1.Schedule{
+				Type:     "fixed",
+				Runat:    "* * * * *",
+				Replicas: &scaledReplica,
+			},
+			expectedReplicas: scaledReplica,
+		},
+		"range scaling custom resource": {
+			target: rollout,
+			schedule: scscv1.Schedule{
+				Type:        "range",
+				Runat:       "* * * * *",
+				MinReplicas: &min,
+				MaxReplicas: &max,
+			},
+			expectedReplicas: min,
+			hpaExists:        true,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			fakeCli := fake.NewFakeClientWithScheme(s)
+			fakeScale := test.NewFakeScaleClient()
+			fakeScale.Set(c.target, "test-ns", replica)
+			testScaler, err := New(fakeCli, fakeScale, &record.FakeRecorder{}, newScheduledScaler(c.target), c.schedule, 0)
+			require.NoError(t, err)
+
+			// do testing function
+			err = testScaler.Run()
+
+			// verify by cases
+			require.NoError(t, err)
+			scaled, err := fakeScale.GetReplicas(c.target, "test-ns")
+			require.NoError(t, err)
+			require.Equal(t, c.expectedReplicas, scaled)
+
+			hpa, err := k8s.GetHpa(fakeCli, k8s.GetHpaName("test-scsc"), "test-ns")
+			require.NoError(t, err)
+			if c.hpaExists {
+				// hpa must refer the target
+				require.NotNil(t, hpa)
+				require.Equal(t, c.target.Kind, hpa.Spec.ScaleTargetRef.Kind)
+				require.Equal(t, c.target.APIVersion, hpa.Spec.ScaleTargetRef.APIVersion)
+				require.Equal(t, c.target.Name, hpa.Spec.ScaleTargetRef.Name)
+			} else {
+				require.Nil(t, hpa)
+			}
+		})
+	}
+}
+
+func TestScaler_RunWithSelector(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(appsv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	replica := int32(1)
+	scaledReplica := int32(2)
+	min := int32(1)
+	max := int32(3)
+	target := scscv1.SchedulingTarget{
+		Selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"tier": "worker"},
+		},
+	}
+	deploy := func(name string, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test-ns",
+				Labels:    labels,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replica,
+			},
+		}
+	}
+
+	tc := map[string]struct {
+		schedule         scscv1.Schedule
+		expectedReplicas int32
+		expectedHpas     []string
+	}{
+		"fixed scaling": {
+			schedule: scscv1.Schedule{
+				Type:     "fixed",
+				Runat:    "* * * * *",
+				Replicas: &scaledReplica,
+			},
+			expectedReplicas: scaledReplica,
+		},
+		"range scaling": {
+			schedule: scscv1.Schedule{
+				Type:        "range",
+				Runat:       "* * * * *",
+				MinReplicas: &min,
+				MaxReplicas: &max,
+			},
+			expectedReplicas: min,
+			expectedHpas:     []string{"test-scsc-worker-1-hpa", "test-scsc-worker-2-hpa"},
+		},
+	}
+
+	recordedReplica := int32(5)
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			// worker-2 started matching the selector after the ScheduledScaler was reconciled
+			scsc := newScheduledScaler(target)
+			scsc.Status.OriginalReplicas = []scscv1.TargetReplicas{{Name: "worker-1", Replicas: recordedReplica}}
+			fakeCli := fake.NewFakeClientWithScheme(s, &scsc,
+				deploy("worker-1", map[string]string{"tier": "worker"}),
+				deploy("worker-2", map[string]string{"tier": "worker"}),
+				deploy("frontend", map[string]string{"tier": "frontend"}),
+				// hpa of the workload which isn't selected anymore
+				&autov2beta2.HorizontalPodAutoscaler{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-scsc-removed-hpa",
+						Namespace: "test-ns",
+						Labels:    map[string]string{"owner": "test-scsc"},
+					},
+				},
+			)
+			fakeScale := test.NewFakeScaleClient()
+			for _, workload := range []string{"worker-1", "worker-2", "frontend"} {
+				fakeScale.Set(scscv1.SchedulingTarget{Name: workload}, "test-ns", replica)
+			}
+			testScaler, err := New(fakeCli, fakeScale, &record.FakeRecorder{}, scsc, c.schedule, 0)
+			require.NoError(t, err)
+
+			// do testing function
+			err = testScaler.Run()
+
+			// verify by cases
+			require.NoError(t, err)
+			// replicas of worker-2 are recorded before it's scaled
+			recorded := &scscv1.ScheduledScaler{}
+			require.NoError(t, fakeCli.Get(context.Background(), client.ObjectKey{Name: scsc.Name, Namespace: scsc.Namespace}, recorded))
+			require.Equal(t, []scscv1.TargetReplicas{
+				{Name: "worker-1", Replicas: recordedReplica},
+				{Name: "worker-2", Replicas: replica},
+			}, recorded.Status.OriginalReplicas)
+			for _, workload := range []string{"worker-1", "worker-2"} {
+				scaled, err := fakeScale.GetReplicas(scscv1.SchedulingTarget{Name: workload}, "test-ns")
+				require.NoError(t, err)
+				require.Equal(t, c.expectedReplicas, scaled)
+			}
+			notSelected, err := fakeScale.GetReplicas(scscv1.SchedulingTarget{Name: "frontend"}, "test-ns")
+			require.NoError(t, err)
+			require.Equal(t, replica, notSelected)
+
+			hpaList := &autov2beta2.HorizontalPodAutoscalerList{}
+			require.NoError(t, fakeCli.List(context.Background(), hpaList))
+			hpaNames := []string{}
+			for _, hpa := range hpaList.Items {
+				hpaNames = append(hpaNames, hpa.Name)
+			}
+			require.ElementsMatch(t, c.expectedHpas, hpaNames)
+		})
+	}
+}
+
+func TestScaler_RunWithMetrics(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	replica := int32(1)
+	min := int32(1)
+	max := int32(3)
+	cpuUtilization := int32(50)
+	memoryUtilization := int32(70)
+	target := scscv1.SchedulingTarget{
+		Name: "test-deploy",
+	}
+	cpuMetrics := []autov2beta2.MetricSpec{
+		{
+			Type: autov2beta2.ResourceMetricSourceType,
+			Resource: &autov2beta2.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: autov2beta2.MetricTarget{
+					Type:               autov2beta2.UtilizationMetricType,
+					AverageUtilization: &cpuUtilization,
+				},
+			},
+		},
+	}
+	memoryMetrics := []autov2beta2.MetricSpec{
+		{
+			Type: autov2beta2.ResourceMetricSourceType,
+			Resource: &autov2beta2.ResourceMetricSource{
+				Name: corev1.ResourceMemory,
+				Target: autov2beta2.MetricTarget{
+					Type:               autov2beta2.UtilizationMetricType,
+					AverageUtilization: &memoryUtilization,
+				},
+			},
+		},
+	}
+
+	tc := map[string]struct {
+		metrics         []autov2beta2.MetricSpec
+		previousMetrics []autov2beta2.MetricSpec
+		expectedMetrics []autov2beta2.MetricSpec
+	}{
+		"default metrics": {
+			expectedMetrics: cpuMetrics,
+		},
+		"custom metrics": {
+			metrics:         memoryMetrics,
+			expectedMetrics: memoryMetrics,
+		},
+		"metrics updated in place": {
+			metrics:         memoryMetrics,
+			previousMetrics: cpuMetrics,
+			expectedMetrics: memoryMetrics,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			fakeCli := fake.NewFakeClientWithScheme(s)
+			fakeScale := test.NewFakeScaleClient()
+			fakeScale.Set(target, "test-ns", replica)
+			if c.previousMetrics != nil {
+				_, err := k8s.UpdateHpa(fakeCli, &k8s.HpaValidationOptions{
+					Namespace:           "test-ns",
+					Target:              target,
+					ScheduledScalerName: "test-scsc",
+					MinReplicas:         &min,
+					MaxReplicas:         &max,
+					Metrics:             c.previousMetrics,
+				})
+				require.NoError(t, err)
+			}
+			testScaler, err := New(fakeCli, fakeScale, &record.FakeRecorder{}, newScheduledScaler(target), scscv1.Schedule{
+				Type:        "range",
+				Runat:       "* * * * *",
+				MinReplicas: &min,
+				MaxReplicas: &max,
+				Metrics:     c.metrics,
+			}, 0)
+			require.NoError(t, err)
+
+			// do testing function
+			err = testScaler.Run()
+
+			// verify by cases
+			require.NoError(t, err)
+			hpa, err := k8s.GetHpa(fakeCli, k8s.GetHpaName("test-scsc"), "test-ns")
+			require.NoError(t, err)
+			require.Equal(t, c.expectedMetrics, hpa.Spec.Metrics)
+		})
+	}
+}
+
+func TestScaler_RunWithBehavior(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	replica := int32(1)
+	min := int32(1)
+	max := int32(3)
+	window := int32(600)
+	target := scscv1.SchedulingTarget{
+		Name: "test-deploy",
+	}
+	slowScaleDown := &autov2beta2.HorizontalPodAutoscalerBehavior{
+		ScaleDown: &autov2beta2.HPAScalingRules{
+			StabilizationWindowSeconds: &window,
+			Policies: []autov2beta2.HPAScalingPolicy{
+				{
+					Type:          autov2beta2.PodsScalingPolicy,
+					Value:         1,
+					PeriodSeconds: 300,
+				},
+			},
+		},
+	}
+	fastScaleUp := &autov2beta2.HorizontalPodAutoscalerBehavior{
+		ScaleUp: &autov2beta2.HPAScalingRules{
+			Policies: []autov2beta2.HPAScalingPolicy{
+				{
+					Type:          autov2beta2.PercentScalingPolicy,
+					Value:         100,
+					PeriodSeconds: 15,
+				},
+			},
+		},
+	}
+
+	tc := map[string]struct {
+		behavior         *autov2beta2.HorizontalPodAutoscalerBehavior
+		previousBehavior *autov2beta2.HorizontalPodAutoscalerBehavior
+	}{
+		"default behavior": {},
+		"behavior set": {
+			behavior: slowScaleDown,
+		},
+		"behavior updated in place": {
+			behavior:         fastScaleUp,
+			previousBehavior: slowScaleDown,
+		},
+		"behavior cleared": {
+			previousBehavior: slowScaleDown,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			fakeCli := fake.NewFakeClientWithScheme(s)
+			fakeScale := test.NewFakeScaleClient()
+			fakeScale.Set(target, "test-ns", replica)
+			if c.previousBehavior != nil {
+				_, err := k8s.UpdateHpa(fakeCli, &k8s.HpaValidationOptions{
+					Namespace:           "test-ns",
+					Target:              target,
+					ScheduledScalerName: "test-scsc",
+					MinReplicas:         &min,
+					MaxReplicas:         &max,
+					Behavior:            c.previousBehavior,
+				})
+				require.NoError(t, err)
+			}
+			testScaler, err := New(fakeCli, fakeScale, &record.FakeRecorder{}, newScheduledScaler(target), scscv1.Schedule{
+				Type:        "range",
+				Runat:       "* * * * *",
+				MinReplicas: &min,
+				MaxReplicas: &max,
+				Behavior:    c.behavior,
+			}, 0)
+			require.NoError(t, err)
+
+			// do testing function
+			err = testScaler.Run()
+
+			// verify by cases
+			require.NoError(t, err)
+			hpa, err := k8s.GetHpa(fakeCli, k8s.GetHpaName("test-scsc"), "test-ns")
+			require.NoError(t, err)
+			require.Equal(t, c.behavior, hpa.Spec.Behavior)
+		})
+	}
+}
+
+func newScheduledScaler(target scscv1.SchedulingTarget) scscv1.ScheduledScaler {
+	return scscv1.ScheduledScaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-scsc",
+			Namespace: "test-ns",
+		},
+		Spec: scscv1.ScheduledScalerSpec{
+			Target: target,
+		},
+	}
+}
+
+func TestScaler_RunWithAdoptedHpa(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	replica := int32(1)
+	scaledReplica := int32(2)
+	min := int32(2)
+	max := int32(5)
+	originalMin := int32(1)
+	utilization := int32(80)
+	target := scscv1.SchedulingTarget{
+		Name: "test-deploy",
+	}
+	userMetrics := []autov2beta2.MetricSpec{
+		{
+			Type: autov2beta2.ResourceMetricSourceType,
+			Resource: &autov2beta2.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: autov2beta2.MetricTarget{
+					Type:               autov2beta2.UtilizationMetricType,
+					AverageUtilization: &utilization,
+				},
+			},
+		},
+	}
+
+	tc := map[string]struct {
+		schedule         scscv1.Schedule
+		expectedMin      int32
+		expectedMax      int32
+		expectedReplicas int32
+	}{
+		"fixed scaling pauses adopted hpa": {
+			schedule: scscv1.Schedule{
+				Type:     "fixed",
+				Runat:    "* * * * *",
+				Replicas: &scaledReplica,
+			},
+			expectedMin:      scaledReplica,
+			expectedMax:      scaledReplica,
+			expectedReplicas: scaledReplica,
+		},
+		"range scaling patches bounds of adopted hpa": {
+			schedule: scscv1.Schedule{
+				Type:        "range",
+				Runat:       "* * * * *",
+				MinReplicas: &min,
+				MaxReplicas: &max,
+			},
+			expectedMin:      min,
+			expectedMax:      max,
+			expectedReplicas: replica,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			fakeCli := fake.NewFakeClientWithScheme(s, &autov2beta2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "user-hpa",
+					Namespace: "test-ns",
+				},
+				Spec: autov2beta2.HorizontalPodAutoscalerSpec{
+					MinReplicas: &originalMin,
+					MaxReplicas: 3,
+					Metrics:     userMetrics,
+				},
+			})
+			fakeScale := test.NewFakeScaleClient()
+			fakeScale.Set(target, "test-ns", replica)
+			scsc := newScheduledScaler(target)
+			scsc.Spec.HpaRef = &corev1.LocalObjectReference{Name: "user-hpa"}
+			testScaler, err := New(fakeCli, fakeScale, &record.FakeRecorder{}, scsc, c.schedule, 0)
+			require.NoError(t, err)
+
+			// do testing function
+			err = testScaler.Run()
+
+			// verify by cases
+			require.NoError(t, err)
+			hpa, err := k8s.GetHpa(fakeCli, "user-hpa", "test-ns")
+			require.NoError(t, err)
+			require.NotNil(t, hpa)
+			require.Equal(t, c.expectedMin, *hpa.Spec.MinReplicas)
+			require.Equal(t, c.expectedMax, hpa.Spec.MaxReplicas)
+			// metrics of adopted hpa must be kept
+			require.Equal(t, userMetrics, hpa.Spec.Metrics)
+			// original bounds must be recorded
+			require.Equal(t, "test-scsc", hpa.Annotations[k8s.AdoptedByAnnotation])
+			require.Equal(t, "1", hpa.Annotations[k8s.OriginalMinReplicasAnnotation])
+			require.Equal(t, "3", hpa.Annotations[k8s.OriginalMaxReplicasAnnotation])
+			// hpa of scheduled scaler must not be created
+			owned, err := k8s.GetHpa(fakeCli, k8s.GetHpaName("test-scsc"), "test-ns")
+			require.NoError(t, err)
+			require.Nil(t, owned)
+
+			scaled, err := fakeScale.GetReplicas(target, "test-ns")
+			require.NoError(t, err)
+			require.Equal(t, c.expectedReplicas, scaled)
+		})
+	}
+}
+
+func TestScaler_RunFailed(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	scaledReplica := int32(2)
+	min := int32(1)
+	max := int32(3)
+	tc := map[string]struct {
+		schedule scscv1.Schedule
+	}{
+		"fixed scaling": {
+			schedule: scscv1.Schedule{
+				Type:     "fixed",
+				Runat:    "* * * * *",
+				Replicas: &scaledReplica,
+			},
+		},
+		"range scaling": {
+			schedule: scscv1.Schedule{
+				Type:        "range",
+				Runat:       "* * * * *",
+				MinReplicas: &min,
+				MaxReplicas: &max,
+			},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			// target doesn't exist, so that scaling fails
+			fakeCli := fake.NewFakeClientWithScheme(s)
+			recorder := record.NewFakeRecorder(10)
+			testScaler, err := New(fakeCli, test.NewFakeScaleClient(), recorder, newScheduledScaler(scscv1.SchedulingTarget{Name: "missing-deploy"}), c.schedule, 0)
+			require.NoError(t, err)
+
+			// do testing function
+			err = testScaler.Run()
+
+			// verify by cases
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "missing-deploy")
+			require.Equal(t, "Warning TargetNotFound Deployment missing-deploy is not found", <-recorder.Events)
+		})
+	}
+}
+
+func TestScaler_RunEvents(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	replica := int32(1)
+	scaledReplica := int32(2)
+	min := int32(1)
+	max := int32(3)
+	target := scscv1.SchedulingTarget{
+		Name: "test-deploy",
+	}
+	tc := map[string]struct {
+		schedule       scscv1.Schedule
+		hpaExists      bool
+		expectedEvents []string
+	}{
+		"fixed scaling deletes hpa": {
+			schedule: scscv1.Schedule{
+				Type:     "fixed",
+				Runat:    "* * * * *",
+				Replicas: &scaledReplica,
+			},
+			hpaExists: true,
+			expectedEvents: []string{
+				"Normal HPADeleted Deleted HPA test-scsc-hpa",
+				"Normal Scaled Scaled Deployment test-deploy to 2 replicas",
+				"Normal Scaled Scaled Deployment test-deploy to 2 replicas by ScheduledScaler test-scsc",
+			},
+		},
+		"range scaling creates hpa": {
+			schedule: scscv1.Schedule{
+				Type:        "range",
+				Runat:       "* * * * *",
+				MinReplicas: &min,
+				MaxReplicas: &max,
+			},
+			expectedEvents: []string{
+				"Normal Scaled Scaled Deployment test-deploy to 1 replicas",
+				"Normal Scaled Scaled Deployment test-deploy to 1 replicas by ScheduledScaler test-scsc",
+				"Normal HPACreated Created HPA test-scsc-hpa with 1-3 replicas",
+			},
+		},
+		"range scaling updates hpa": {
+			schedule: scscv1.Schedule{
+				Type:        "range",
+				Runat:       "* * * * *",
+				MinReplicas: &min,
+				MaxReplicas: &max,
+			},
+			hpaExists: true,
+			expectedEvents: []string{
+				"Normal Scaled Scaled Deployment test-deploy to 1 replicas",
+				"Normal Scaled Scaled Deployment test-deploy to 1 replicas by ScheduledScaler test-scsc",
+				"Normal HPAUpdated Updated HPA test-scsc-hpa to 1-3 replicas",
+			},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			fakeCli := fake.NewFakeClientWithScheme(s)
+			fakeScale := test.NewFakeScaleClient()
+			fakeScale.Set(target, "test-ns", replica)
+			if c.hpaExists {
+				_, err := k8s.UpdateHpa(fakeCli, &k8s.HpaValidationOptions{
+					Namespace:           "test-ns",
+					Target:              target,
+					ScheduledScalerName: "test-scsc",
+					MinReplicas:         &min,
+					MaxReplicas:         &max,
+				})
+				require.NoError(t, err)
+			}
+			recorder := record.NewFakeRecorder(10)
+			testScaler, err := New(fakeCli, fakeScale, recorder, newScheduledScaler(target), c.schedule, 0)
+			require.NoError(t, err)
+
+			// do testing function
+			err = testScaler.Run()
+
+			// verify by cases
+			require.NoError(t, err)
+			close(recorder.Events)
+			events := []string{}
+			for event := range recorder.Events {
+				events = append(events, event)
+			}
+			require.Equal(t, c.expectedEvents, events)
+		})
+	}
+}
+
+func TestScaler_Sync(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	utilruntime.Must(autov2beta2.AddToScheme(s))
+
+	replica := int32(1)
+	min := int32(1)
+	max := int32(3)
+	editedMax := int32(10)
+	target := scscv1.SchedulingTarget{
+		Name: "test-deploy",
+	}
+	tc := map[string]struct {
+		scheduleType string
+		// edit is applied to the HPA created by the run. The HPA is deleted when it's nil
+		edit           func(*autov2beta2.HorizontalPodAutoscaler)
+		expectedHpa    bool
+		expectedEvents []string
+	}{
+		"range scaler repairs edited HPA": {
+			scheduleType: "range",
+			edit: func(hpa *autov2beta2.HorizontalPodAutoscaler) {
+				hpa.Spec.MaxReplicas = editedMax
+			},
+			expectedHpa:    true,
+			expectedEvents: []string{ReasonHPARepaired},
+		},
+		"range scaler recreates deleted HPA": {
+			scheduleType:   "range",
+			expectedHpa:    true,
+			expectedEvents: []string{ReasonHPARepaired},
+		},
+		"range scaler leaves HPA not drifted": {
+			scheduleType:   "range",
+			edit:           func(*autov2beta2.HorizontalPodAutoscaler) {},
+			expectedHpa:    true,
+			expectedEvents: []string{},
+		},
+		"fixed scaler manages no HPA": {
+			scheduleType:   "fixed",
+			expectedHpa:    false,
+			expectedEvents: []string{},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			fakeCli := fake.NewFakeClientWithScheme(s)
+			fakeScale := test.NewFakeScaleClient()
+			fakeScale.Set(target, "test-ns", replica)
+			scsc := newScheduledScaler(target)
+			scsc.UID = "test-uid"
+			recorder := record.NewFakeRecorder(10)
+			testScaler, err := New(fakeCli, fakeScale, recorder, scsc, scscv1.Schedule{
+				Type:        c.scheduleType,
+				Runat:       "* * * * *",
+				Replicas:    &max,
+				MinReplicas: &min,
+				MaxReplicas: &max,
+			}, 0)
+			require.NoError(t, err)
+			require.NoError(t, testScaler.Run())
+			for len(recorder.Events) > 0 {
+				<-recorder.Events
+			}
+			hpa := &autov2beta2.HorizontalPodAutoscaler{}
+			getErr := fakeCli.Get(context.Background(), client.ObjectKey{Name: k8s.GetHpaName("test-scsc"), Namespace: "test-ns"}, hpa)
+			if c.scheduleType == "range" {
+				require.NoError(t, getErr)
+				require.Len(t, hpa.OwnerReferences, 1)
+				require.Equal(t, scsc.UID, hpa.OwnerReferences[0].UID)
+				require.True(t, *hpa.OwnerReferences[0].Controller)
+				if c.edit != nil {
+					c.edit(hpa)
+					require.NoError(t, fakeCli.Update(context.Background(), hpa))
+				} else {
+					require.NoError(t, fakeCli.Delete(context.Background(), hpa))
+				}
+			}
+
+			// do testing function
+			err = testScaler.Sync()
+
+			// verify by cases
+			require.NoError(t, err)
+			result, err := k8s.GetHpa(fakeCli, k8s.GetHpaName("test-scsc"), "test-ns")
+			require.NoError(t, err)
+			if c.expectedHpa {
+				require.NotNil(t, result)
+				require.Equal(t, max, result.Spec.MaxReplicas)
+			} else {
+				require.Nil(t, result)
+			}
+			close(recorder.Events)
+			reasons := []string{}
+			for event := range recorder.Events {
+				reasons = append(reasons, strings.Fields(event)[1])
+			}
+			require.Equal(t, c.expectedEvents, reasons)
+		})
+	}
+}
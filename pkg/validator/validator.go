@@ -1,9 +1,30 @@
 package validator
 
-import scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+import (
+	"fmt"
+	"time"
 
+	robfigCron "github.com/robfig/cron"
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/oneoff"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/blackout"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// overlapCheckCount is how many upcoming times of a schedule are checked to find overlaps
+const overlapCheckCount = 1000
+
+// Types of schedule
+const (
+	TypeFixed = "fixed"
+	TypeRange = "range"
+)
+
+// Validator validates spec of ScheduledScaler: targets, cron expressions, time zone, bounds of replicas and types of schedules.
+// Every error is returned with the path of the field, e.g. spec.schedule[2].maxReplicas
 type Validator interface {
-	Validate() bool
+	Validate() field.ErrorList
 }
 
 type ValidatorImpl struct {
@@ -16,42 +37,320 @@ func New(source scscv1.ScheduledScaler) Validator {
 	}
 }
 
-func (v *ValidatorImpl) Validate() bool {
-	for _, schedule := range v.source.Spec.Schedule {
-		if schedule.Type == "fixed" {
-			if !v.fixedScheduleValidate(schedule) {
-				return false
+func (v *ValidatorImpl) Validate() field.ErrorList {
+	spec := v.source.Spec
+	specPath := field.NewPath("spec")
+	errs := v.targetValidate(specPath.Child("target"), spec.Target)
+
+	if spec.TimeZone != "" {
+		if _, err := time.LoadLocation(spec.TimeZone); err != nil {
+			errs = append(errs, field.Invalid(specPath.Child("timeZone"), spec.TimeZone, err.Error()))
+		}
+	}
+
+	if deadline := spec.StartingDeadlineSeconds; deadline != nil {
+		errs = append(errs, apivalidation.ValidateNonnegativeField(*deadline, specPath.Child("startingDeadlineSeconds"))...)
+	}
+
+	if hpaRef := spec.HpaRef; hpaRef != nil {
+		// existing HPA can be adopted only for a single target
+		if hpaRef.Name == "" {
+			errs = append(errs, field.Required(specPath.Child("hpaRef", "name"), ""))
+		}
+		if spec.Target.Selector != nil {
+			errs = append(errs, field.Forbidden(specPath.Child("hpaRef"), "may not be set with target selector"))
+		}
+	}
+
+	if policy := spec.DeletionPolicy; policy != nil {
+		// replicas is required only for Scale policy
+		replicasPath := specPath.Child("deletionPolicy", "replicas")
+		if policy.Type == scscv1.DeletionPolicyScale && policy.Replicas == nil {
+			errs = append(errs, field.Required(replicasPath, "must be set for Scale policy"))
+		} else if policy.Type != scscv1.DeletionPolicyScale && policy.Replicas != nil {
+			errs = append(errs, field.Forbidden(replicasPath, "may be set only for Scale policy"))
+		}
+		if policy.Replicas != nil {
+			errs = append(errs, apivalidation.ValidateNonnegativeField(int64(*policy.Replicas), replicasPath)...)
+		}
+	}
+
+	if ref := spec.CalendarRef; ref != nil {
+		refPath := specPath.Child("calendarRef")
+		if ref.Name == "" {
+			errs = append(errs, field.Required(refPath.Child("name"), ""))
+		}
+		for i, override := range ref.Overrides {
+			overridePath := refPath.Child("overrides").Index(i)
+			if override.Name == "" {
+				errs = append(errs, field.Required(overridePath.Child("name"), ""))
+			}
+			errs = append(errs, replicasValidate(overridePath, override.Replicas, override.MinReplicas, override.MaxReplicas)...)
+		}
+	} else if len(spec.Schedule) == 0 && spec.Default == nil {
+		// schedules come from either spec or calendar
+		errs = append(errs, field.Required(specPath.Child("schedule"), "either schedule, default or calendarRef must be set"))
+	}
+
+	if calendar := spec.Blackout; calendar != nil {
+		if _, err := blackout.Parse(calendar.Dates, time.UTC); err != nil {
+			errs = append(errs, field.Invalid(specPath.Child("blackout", "dates"), calendar.Dates, err.Error()))
+		}
+	}
+
+	schedulePath := specPath.Child("schedule")
+	for i, schedule := range spec.Schedule {
+		errs = append(errs, v.scheduleTimeValidate(schedulePath.Index(i), schedule)...)
+		errs = append(errs, v.scheduleValidate(schedulePath.Index(i), schedule)...)
+	}
+
+	errs = append(errs, v.overlapValidate(schedulePath, spec.Schedule)...)
+
+	if defaultSchedule := spec.Default; defaultSchedule != nil {
+		defaultPath := specPath.Child("default")
+		// default is applied out of every window, so it has no time
+		errs = append(errs, forbidSetFields(defaultPath, "may not be set for default", []setField{
+			{"runat", defaultSchedule.Runat != ""},
+			{"start", defaultSchedule.Start != ""},
+			{"end", defaultSchedule.End != ""},
+			{"duration", defaultSchedule.Duration != nil},
+			{"at", defaultSchedule.At != nil},
+			{"until", defaultSchedule.Until != nil},
+		})...)
+		errs = append(errs, v.scheduleValidate(defaultPath, *defaultSchedule)...)
+	}
+
+	return errs
+}
+
+func (v *ValidatorImpl) scheduleValidate(path *field.Path, schedule scscv1.Schedule) field.ErrorList {
+	switch schedule.Type {
+	case TypeFixed:
+		return v.fixedScheduleValidate(path, schedule)
+	case TypeRange:
+		return v.rangeScheduleValidate(path, schedule)
+	case "":
+		return field.ErrorList{field.Required(path.Child("type"), "")}
+	default:
+		return field.ErrorList{field.NotSupported(path.Child("type"), schedule.Type, []string{TypeFixed, TypeRange})}
+	}
+}
+
+func (v *ValidatorImpl) scheduleTimeValidate(path *field.Path, schedule scscv1.Schedule) field.ErrorList {
+	// one of runat, start and at must be set
+	set := 0
+	for _, isSet := range []bool{schedule.Runat != "", schedule.Start != "", schedule.At != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set == 0 {
+		return field.ErrorList{field.Required(path, "one of runat, start and at must be set")}
+	} else if set > 1 {
+		return field.ErrorList{field.Invalid(path, schedule, "only one of runat, start and at may be set")}
+	}
+
+	errs := field.ErrorList{}
+	if schedule.Duration != nil && schedule.Duration.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("duration"), schedule.Duration.Duration.String(), "must be positive"))
+	}
+
+	switch {
+	case schedule.Runat != "":
+		errs = append(errs, forbidSetFields(path, "may not be set with runat", []setField{
+			{"end", schedule.End != ""},
+			{"duration", schedule.Duration != nil},
+			{"until", schedule.Until != nil},
+		})...)
+		errs = append(errs, cronValidate(path.Child("runat"), schedule.Runat)...)
+	case schedule.Start != "":
+		// window needs either end or duration
+		if schedule.End == "" && schedule.Duration == nil {
+			errs = append(errs, field.Required(path.Child("end"), "either end or duration must be set with start"))
+		} else if schedule.End != "" && schedule.Duration != nil {
+			errs = append(errs, field.Forbidden(path.Child("duration"), "may not be set with end"))
+		}
+		if schedule.Until != nil {
+			errs = append(errs, field.Forbidden(path.Child("until"), "may not be set with start"))
+		}
+		errs = append(errs, cronValidate(path.Child("start"), schedule.Start)...)
+		if schedule.End != "" {
+			errs = append(errs, cronValidate(path.Child("end"), schedule.End)...)
+		}
+	default:
+		// one-off window needs either until or duration, and one-off point needs neither
+		if schedule.End != "" {
+			errs = append(errs, field.Forbidden(path.Child("end"), "may not be set with at"))
+		}
+		if schedule.Until != nil && schedule.Duration != nil {
+			errs = append(errs, field.Forbidden(path.Child("duration"), "may not be set with until"))
+		}
+		if schedule.Until != nil && !schedule.Until.After(schedule.At.Time) {
+			errs = append(errs, field.Invalid(path.Child("until"), schedule.Until.String(), "must be after at"))
+		}
+	}
+
+	return errs
+}
+
+// overlapValidate checks that schedules of the same priority aren't due at the same time,
+// because which one is applied can't be told from the spec
+func (v *ValidatorImpl) overlapValidate(path *field.Path, schedules []scscv1.Schedule) field.ErrorList {
+	errs := field.ErrorList{}
+	now := time.Now()
+	for i := range schedules {
+		for j := i + 1; j < len(schedules); j++ {
+			a, b := schedules[i], schedules[j]
+			// point schedules collide by due times, and windows collide by starts
+			if a.Priority != b.Priority || isWindow(a) != isWindow(b) {
+				continue
 			}
-		} else {
-			if !v.rangeScheduleValidate(schedule) {
-				return false
+
+			if coincide(startSchedule(a), startSchedule(b), now) {
+				errs = append(errs, field.Invalid(path.Index(j).Child("priority"), b.Priority,
+					fmt.Sprintf("must differ from priority of %s, which is due at the same time", path.Index(i))))
 			}
 		}
 	}
 
-	return true
+	return errs
 }
 
-func (v *ValidatorImpl) fixedScheduleValidate(schedule scscv1.Schedule) bool {
-	if schedule.Replicas == nil {
-		return false
+func isWindow(schedule scscv1.Schedule) bool {
+	return schedule.Start != "" || (schedule.At != nil && (schedule.Until != nil || schedule.Duration != nil))
+}
+
+// startSchedule returns the schedule of runat, start or at. It returns nil if the cron expression is invalid
+func startSchedule(schedule scscv1.Schedule) robfigCron.Schedule {
+	if schedule.At != nil {
+		return oneoff.Schedule{At: schedule.At.Time}
+	}
+
+	spec := schedule.Runat
+	if schedule.Start != "" {
+		spec = schedule.Start
+	}
+	parsed, err := robfigCron.Parse(spec)
+	if err != nil {
+		return nil
 	}
+	return parsed
+}
+
+// setField is whether the field under a path is set
+type setField struct {
+	name  string
+	isSet bool
+}
+
+// forbidSetFields returns forbidden errors of fields which are set
+func forbidSetFields(path *field.Path, detail string, fields []setField) field.ErrorList {
+	errs := field.ErrorList{}
+	for _, f := range fields {
+		if f.isSet {
+			errs = append(errs, field.Forbidden(path.Child(f.name), detail))
+		}
+	}
+	return errs
+}
+
+// cronValidate checks syntax of the cron expression, and that it's due at least once, e.g. "0 0 0 30 2 *" is never due
+func cronValidate(path *field.Path, spec string) field.ErrorList {
+	parsed, err := robfigCron.Parse(spec)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, spec, err.Error())}
+	}
+	if parsed.Next(time.Now()).IsZero() {
+		return field.ErrorList{field.Invalid(path, spec, "is never due")}
+	}
+	return nil
+}
 
-	if schedule.MinReplicas != nil || schedule.MaxReplicas != nil {
+// replicasValidate checks bounds of replicas which are set. Replicas can be 0, but HPA needs at least 1 replica
+func replicasValidate(path *field.Path, replicas, minReplicas, maxReplicas *int32) field.ErrorList {
+	errs := field.ErrorList{}
+	if replicas != nil {
+		errs = append(errs, apivalidation.ValidateNonnegativeField(int64(*replicas), path.Child("replicas"))...)
+	}
+	if minReplicas != nil && *minReplicas < 1 {
+		errs = append(errs, field.Invalid(path.Child("minReplicas"), *minReplicas, "must be >= 1"))
+	}
+	if maxReplicas != nil && *maxReplicas < 1 {
+		errs = append(errs, field.Invalid(path.Child("maxReplicas"), *maxReplicas, "must be >= 1"))
+	}
+	if minReplicas != nil && maxReplicas != nil && *maxReplicas < *minReplicas {
+		errs = append(errs, field.Invalid(path.Child("maxReplicas"), *maxReplicas, "must be >= minReplicas"))
+	}
+	return errs
+}
+
+// coincide returns whether b is due at any of upcoming times of a
+func coincide(a, b robfigCron.Schedule, from time.Time) bool {
+	if a == nil || b == nil {
 		return false
 	}
 
-	return true
+	// one-off schedule in the past is compared from its time
+	if once, ok := a.(oneoff.Schedule); ok && once.At.Before(from) {
+		from = once.At.Add(-time.Second)
+	}
+
+	next := from
+	for i := 0; i < overlapCheckCount; i++ {
+		next = a.Next(next)
+		if next.IsZero() {
+			return false
+		}
+		if b.Next(next.Add(-time.Second)).Equal(next) {
+			return true
+		}
+	}
+
+	return false
 }
 
-func (v *ValidatorImpl) rangeScheduleValidate(schedule scscv1.Schedule) bool {
+func (v *ValidatorImpl) targetValidate(path *field.Path, target scscv1.SchedulingTarget) field.ErrorList {
+	// either name or selector must be set
+	if target.Name == "" && target.Selector == nil {
+		return field.ErrorList{field.Required(path, "either name or selector must be set")}
+	} else if target.Name != "" && target.Selector != nil {
+		return field.ErrorList{field.Forbidden(path.Child("selector"), "may not be set with name")}
+	}
+	return nil
+}
+
+func (v *ValidatorImpl) fixedScheduleValidate(path *field.Path, schedule scscv1.Schedule) field.ErrorList {
+	errs := field.ErrorList{}
+	if schedule.Replicas == nil {
+		errs = append(errs, field.Required(path.Child("replicas"), "must be set for fixed schedule"))
+	} else {
+		errs = append(errs, replicasValidate(path, schedule.Replicas, nil, nil)...)
+		// adopted HPA is pinned to the replicas, which needs at least 1 replica
+		if v.source.Spec.HpaRef != nil && *schedule.Replicas < 1 {
+			errs = append(errs, field.Invalid(path.Child("replicas"), *schedule.Replicas, "must be >= 1 when hpaRef is set"))
+		}
+	}
+
+	return append(errs, forbidSetFields(path, "may not be set for fixed schedule", []setField{
+		{"minReplicas", schedule.MinReplicas != nil},
+		{"maxReplicas", schedule.MaxReplicas != nil},
+		{"metrics", len(schedule.Metrics) > 0},
+		{"behavior", schedule.Behavior != nil},
+	})...)
+}
+
+func (v *ValidatorImpl) rangeScheduleValidate(path *field.Path, schedule scscv1.Schedule) field.ErrorList {
+	errs := field.ErrorList{}
 	if schedule.Replicas != nil {
-		return false
+		errs = append(errs, field.Forbidden(path.Child("replicas"), "may not be set for range schedule"))
 	}
 
-	if schedule.MinReplicas == nil || schedule.MaxReplicas == nil {
-		return false
+	if schedule.MinReplicas == nil {
+		errs = append(errs, field.Required(path.Child("minReplicas"), "must be set for range schedule"))
+	}
+	if schedule.MaxReplicas == nil {
+		errs = append(errs, field.Required(path.Child("maxReplicas"), "must be set for range schedule"))
 	}
 
-	return true
+	return append(errs, replicasValidate(path, nil, schedule.MinReplicas, schedule.MaxReplicas)...)
 }
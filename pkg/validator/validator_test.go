@@ -2,9 +2,12 @@ package validator
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	autov2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -23,6 +26,9 @@ func TestValidator_Validate(t *testing.T) {
 					Namespace: "test-ns",
 				},
 				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
 					Schedule: []scscv1.Schedule{
 						{
 							Type:     "fixed",
@@ -41,6 +47,9 @@ func TestValidator_Validate(t *testing.T) {
 					Namespace: "test-ns",
 				},
 				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
 					Schedule: []scscv1.Schedule{
 						{
 							Type:  "fixed",
@@ -58,6 +67,9 @@ func TestValidator_Validate(t *testing.T) {
 					Namespace: "test-ns",
 				},
 				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
 					Schedule: []scscv1.Schedule{
 						{
 							Type:        "fixed",
@@ -70,6 +82,54 @@ func TestValidator_Validate(t *testing.T) {
 			},
 			valid: false,
 		},
+		"fixed invalid: metrics is input": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Replicas: &replica,
+							Metrics: []autov2beta2.MetricSpec{
+								{
+									Type: autov2beta2.ResourceMetricSourceType,
+								},
+							},
+						},
+					},
+				},
+			},
+			valid: false,
+		},
+		"fixed invalid: behavior is input": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Replicas: &replica,
+							Behavior: &autov2beta2.HorizontalPodAutoscalerBehavior{},
+						},
+					},
+				},
+			},
+			valid: false,
+		},
 		"range valid": {
 			scsc: &scscv1.ScheduledScaler{
 				ObjectMeta: metav1.ObjectMeta{
@@ -77,6 +137,9 @@ func TestValidator_Validate(t *testing.T) {
 					Namespace: "test-ns",
 				},
 				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
 					Schedule: []scscv1.Schedule{
 						{
 							Type:        "range",
@@ -96,6 +159,9 @@ func TestValidator_Validate(t *testing.T) {
 					Namespace: "test-ns",
 				},
 				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
 					Schedule: []scscv1.Schedule{
 						{
 							Type:        "range",
@@ -114,6 +180,9 @@ func TestValidator_Validate(t *testing.T) {
 					Namespace: "test-ns",
 				},
 				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
 					Schedule: []scscv1.Schedule{
 						{
 							Type:        "range",
@@ -132,6 +201,9 @@ func TestValidator_Validate(t *testing.T) {
 					Namespace: "test-ns",
 				},
 				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
 					Schedule: []scscv1.Schedule{
 						{
 							Type:  "range",
@@ -149,6 +221,9 @@ func TestValidator_Validate(t *testing.T) {
 					Namespace: "test-ns",
 				},
 				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
 					Schedule: []scscv1.Schedule{
 						{
 							Type:     "range",
@@ -160,23 +235,773 @@ func TestValidator_Validate(t *testing.T) {
 			},
 			valid: false,
 		},
-	}
-
-	for name, c := range tc {
-		t.Run(name, func(t *testing.T) {
-			// set test case
-			testValidator := New(*c.scsc)
-
-			// do testing function
-			valid := testValidator.Validate()
-
-			// verify by cases
-			if c.valid {
-				// when valid scsc is input
-				require.True(t, valid)
-			} else {
-				require.False(t, valid)
+		"selector valid": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Selector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"tier": "worker"},
+						},
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: true,
+		},
+		"target invalid: both name and selector are input": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+						Selector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"tier": "worker"},
+						},
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: false,
+		},
+		"hpaRef invalid: selector target": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Selector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"tier": "worker"},
+						},
+					},
+					HpaRef: &corev1.LocalObjectReference{
+						Name: "user-hpa",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: false,
+		},
+		"target invalid: missing name and selector": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: false,
+		},
+		"deletionPolicy valid: scale with replicas": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					DeletionPolicy: &scscv1.DeletionPolicy{
+						Type:     scscv1.DeletionPolicyScale,
+						Replicas: &replica,
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: true,
+		},
+		"deletionPolicy invalid: scale without replicas": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					DeletionPolicy: &scscv1.DeletionPolicy{
+						Type: scscv1.DeletionPolicyScale,
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: false,
+		},
+		"deletionPolicy invalid: restore with replicas": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					DeletionPolicy: &scscv1.DeletionPolicy{
+						Type:     scscv1.DeletionPolicyRestore,
+						Replicas: &replica,
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: false,
+		},
+		"window valid: start and end": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Start:    "0 0 9 * * 1-5",
+							End:      "0 0 18 * * 1-5",
+							Replicas: &replica,
+						},
+					},
+					Default: &scscv1.Schedule{
+						Type:     "fixed",
+						Replicas: &replica,
+					},
+				},
+			},
+			valid: true,
+		},
+		"window valid: start and duration": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Start:    "0 0 9 * * 1-5",
+							Duration: &metav1.Duration{Duration: time.Hour},
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: true,
+		},
+		"window invalid: missing end": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Start:    "0 0 9 * * 1-5",
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: false,
+		},
+		"window invalid: runat and start": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Start:    "0 0 9 * * 1-5",
+							End:      "0 0 18 * * 1-5",
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: false,
+		},
+		"default invalid: runat is input": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Start:    "0 0 9 * * 1-5",
+							End:      "0 0 18 * * 1-5",
+							Replicas: &replica,
+						},
+					},
+					Default: &scscv1.Schedule{
+						Type:     "fixed",
+						Runat:    "* * * * *",
+						Replicas: &replica,
+					},
+				},
+			},
+			valid: false,
+		},
+		"default invalid: no replicas": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Start:    "0 0 9 * * 1-5",
+							End:      "0 0 18 * * 1-5",
+							Replicas: &replica,
+						},
+					},
+					Default: &scscv1.Schedule{
+						Type: "fixed",
+					},
+				},
+			},
+			valid: false,
+		},
+		"overlap invalid: point schedules due at the same time": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "0 0 9 * * *",
+							Replicas: &replica,
+						},
+						{
+							Type:     "fixed",
+							Runat:    "0 0 9 * * 1-5",
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: false,
+		},
+		"overlap valid: point schedules of different priorities": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "0 0 9 * * *",
+							Replicas: &replica,
+						},
+						{
+							Type:     "fixed",
+							Runat:    "0 0 9 * * 1-5",
+							Priority: 1,
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: true,
+		},
+		"overlap valid: point schedules due at different times": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "0 0 9 * * *",
+							Replicas: &replica,
+						},
+						{
+							Type:     "fixed",
+							Runat:    "0 0 10 * * *",
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: true,
+		},
+		"overlap invalid: windows starting at the same time": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Start:    "0 0 9 * * *",
+							End:      "0 0 18 * * *",
+							Replicas: &replica,
+						},
+						{
+							Type:     "fixed",
+							Start:    "0 0 9 * * *",
+							End:      "0 0 18 * * *",
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: false,
+		},
+		"cron invalid: wrong runat": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "every minute",
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: false,
+		},
+		"one-off valid: at": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							At:       &metav1.Time{Time: time.Date(2026, 11, 27, 0, 0, 0, 0, time.UTC)},
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: true,
+		},
+		"one-off valid: at and until": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							At:       &metav1.Time{Time: time.Date(2026, 11, 27, 0, 0, 0, 0, time.UTC)},
+							Until:    &metav1.Time{Time: time.Date(2026, 11, 30, 23, 59, 0, 0, time.UTC)},
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: true,
+		},
+		"one-off invalid: until before at": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							At:       &metav1.Time{Time: time.Date(2026, 11, 27, 0, 0, 0, 0, time.UTC)},
+							Until:    &metav1.Time{Time: time.Date(2026, 11, 26, 0, 0, 0, 0, time.UTC)},
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: false,
+		},
+		"one-off invalid: at and runat": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							At:       &metav1.Time{Time: time.Date(2026, 11, 27, 0, 0, 0, 0, time.UTC)},
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: false,
+		},
+		"blackout valid": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Blackout: &scscv1.Blackout{
+						Dates: []string{"2026-12-25", "2026-11-27/2026-11-30"},
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: true,
+		},
+		"blackout invalid: wrong date": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					Blackout: &scscv1.Blackout{
+						Dates: []string{"12/25"},
+					},
+					Schedule: []scscv1.Schedule{
+						{
+							Type:     "fixed",
+							Runat:    "* * * * *",
+							Replicas: &replica,
+						},
+					},
+				},
+			},
+			valid: false,
+		},
+		"calendar valid: schedules from calendar": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					CalendarRef: &scscv1.CalendarReference{
+						Name: "test-calendar",
+						Overrides: []scscv1.ScheduleOverride{
+							{Name: "business-hours", Replicas: &replica},
+						},
+					},
+				},
+			},
+			valid: true,
+		},
+		"calendar invalid: override without name": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+					CalendarRef: &scscv1.CalendarReference{
+						Name: "test-calendar",
+						Overrides: []scscv1.ScheduleOverride{
+							{Replicas: &replica},
+						},
+					},
+				},
+			},
+			valid: false,
+		},
+		"schedule invalid: neither schedule nor calendar": {
+			scsc: &scscv1.ScheduledScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-scsc",
+					Namespace: "test-ns",
+				},
+				Spec: scscv1.ScheduledScalerSpec{
+					Target: scscv1.SchedulingTarget{
+						Name: "test-deploy",
+					},
+				},
+			},
+			valid: false,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			testValidator := New(*c.scsc)
+
+			// do testing function
+			valid := len(testValidator.Validate()) == 0
+
+			// verify by cases
+			if c.valid {
+				// when valid scsc is input
+				require.True(t, valid)
+			} else {
+				require.False(t, valid)
+			}
+		})
+	}
+}
+
+func TestValidator_Validate_FieldErrors(t *testing.T) {
+	replica := int32(1)
+	min := int32(3)
+	max := int32(1)
+	zero := int32(0)
+	negative := int32(-1)
+	negativeDeadline := int64(-1)
+	tc := map[string]struct {
+		spec           scscv1.ScheduledScalerSpec
+		expectedErrors []string
+	}{
+		"maxReplicas is less than minReplicas": {
+			spec: scscv1.ScheduledScalerSpec{
+				Target: scscv1.SchedulingTarget{Name: "test-deploy"},
+				Schedule: []scscv1.Schedule{
+					{Type: "fixed", Runat: "0 0 9 * * *", Replicas: &replica},
+					{Type: "range", Runat: "0 0 18 * * *", MinReplicas: &min, MaxReplicas: &max},
+				},
+			},
+			expectedErrors: []string{
+				"spec.schedule[1].maxReplicas: Invalid value: 1: must be >= minReplicas",
+			},
+		},
+		"every invalid field is reported": {
+			spec: scscv1.ScheduledScalerSpec{
+				Schedule: []scscv1.Schedule{
+					{Type: "fixed", Runat: "invalid"},
+				},
+				Default: &scscv1.Schedule{Type: "fixed", Runat: "0 0 9 * * *", Replicas: &replica},
+			},
+			expectedErrors: []string{
+				"spec.target: Required value: either name or selector must be set",
+				`spec.schedule[0].runat: Invalid value: "invalid": Expected 5 to 6 fields, found 1: invalid`,
+				"spec.schedule[0].replicas: Required value: must be set for fixed schedule",
+				"spec.default.runat: Forbidden: may not be set for default",
+			},
+		},
+		"unknown time zone and negative deadline": {
+			spec: scscv1.ScheduledScalerSpec{
+				TimeZone:                "Mars/Olympus",
+				StartingDeadlineSeconds: &negativeDeadline,
+				Target:                  scscv1.SchedulingTarget{Name: "test-deploy"},
+				Schedule: []scscv1.Schedule{
+					{Type: "fixed", Runat: "0 0 9 * * *", Replicas: &replica},
+				},
+			},
+			expectedErrors: []string{
+				`spec.timeZone: Invalid value: "Mars/Olympus": unknown time zone Mars/Olympus`,
+				"spec.startingDeadlineSeconds: Invalid value: -1: must be greater than or equal to 0",
+			},
+		},
+		"cron is never due and replicas are out of bounds": {
+			spec: scscv1.ScheduledScalerSpec{
+				Target: scscv1.SchedulingTarget{Name: "test-deploy"},
+				Schedule: []scscv1.Schedule{
+					{Type: "fixed", Runat: "0 0 0 30 2 *", Replicas: &negative},
+					{Type: "range", Runat: "0 0 18 * * *", MinReplicas: &zero, MaxReplicas: &max},
+				},
+			},
+			expectedErrors: []string{
+				`spec.schedule[0].runat: Invalid value: "0 0 0 30 2 *": is never due`,
+				"spec.schedule[0].replicas: Invalid value: -1: must be greater than or equal to 0",
+				"spec.schedule[1].minReplicas: Invalid value: 0: must be >= 1",
+			},
+		},
+		"unknown and missing types": {
+			spec: scscv1.ScheduledScalerSpec{
+				Target: scscv1.SchedulingTarget{Name: "test-deploy"},
+				Schedule: []scscv1.Schedule{
+					{Type: "scale", Runat: "0 0 9 * * *", Replicas: &replica},
+				},
+				Default: &scscv1.Schedule{Replicas: &replica},
+			},
+			expectedErrors: []string{
+				`spec.schedule[0].type: Unsupported value: "scale": supported values: "fixed", "range"`,
+				"spec.default.type: Required value",
+			},
+		},
+		"fixed replicas is 0 with hpaRef": {
+			spec: scscv1.ScheduledScalerSpec{
+				Target: scscv1.SchedulingTarget{Name: "test-deploy"},
+				HpaRef: &corev1.LocalObjectReference{Name: "user-hpa"},
+				Schedule: []scscv1.Schedule{
+					{Type: "fixed", Runat: "0 0 9 * * *", Replicas: &zero},
+				},
+			},
+			expectedErrors: []string{
+				"spec.schedule[0].replicas: Invalid value: 0: must be >= 1 when hpaRef is set",
+			},
+		},
+		"overrides of calendar are out of bounds": {
+			spec: scscv1.ScheduledScalerSpec{
+				Target: scscv1.SchedulingTarget{Name: "test-deploy"},
+				CalendarRef: &scscv1.CalendarReference{
+					Name: "test-calendar",
+					Overrides: []scscv1.ScheduleOverride{
+						{Name: "business-hours", MinReplicas: &min, MaxReplicas: &max},
+					},
+				},
+			},
+			expectedErrors: []string{
+				"spec.calendarRef.overrides[0].maxReplicas: Invalid value: 1: must be >= minReplicas",
+			},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			testValidator := New(scscv1.ScheduledScaler{Spec: c.spec})
+
+			// do testing function
+			errs := testValidator.Validate()
+
+			// verify by cases
+			messages := []string{}
+			for _, err := range errs {
+				messages = append(messages, err.Error())
 			}
+			require.Equal(t, c.expectedErrors, messages)
 		})
 	}
 }
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"github.com/tmax-cloud/scheduled-scaler-operator/internal/k8s"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/validator"
+)
+
+// DefaultScheduledScaler fills in the effective spec: kind and apiVersion of the target, types of schedules,
+// and the default time zone. The time zone isn't filled in when the calendar is referred, because the calendar's is used
+func DefaultScheduledScaler(scsc *scscv1.ScheduledScaler, defaultTimeZone string) {
+	spec := &scsc.Spec
+	spec.Target.Kind = k8s.GetTargetKind(spec.Target)
+	spec.Target.APIVersion = k8s.GetTargetAPIVersion(spec.Target)
+	if spec.TimeZone == "" && spec.CalendarRef == nil {
+		spec.TimeZone = defaultTimeZone
+	}
+	defaultSchedules(spec.Schedule, spec.Default)
+}
+
+// DefaultScheduleCalendar fills in types of schedules and the default time zone
+func DefaultScheduleCalendar(calendar *scscv1.ScheduleCalendar, defaultTimeZone string) {
+	spec := &calendar.Spec
+	if spec.TimeZone == "" {
+		spec.TimeZone = defaultTimeZone
+	}
+	defaultSchedules(spec.Schedule, spec.Default)
+}
+
+func defaultSchedules(schedules []scscv1.Schedule, defaultSchedule *scscv1.Schedule) {
+	for i := range schedules {
+		inferType(&schedules[i])
+	}
+	if defaultSchedule != nil {
+		inferType(defaultSchedule)
+	}
+}
+
+// inferType sets type of the schedule which has only replicas, or only minReplicas and maxReplicas.
+// Schedules with both are left to be rejected by validation
+func inferType(schedule *scscv1.Schedule) {
+	if schedule.Type != "" {
+		return
+	}
+
+	hasRange := schedule.MinReplicas != nil || schedule.MaxReplicas != nil
+	switch {
+	case schedule.Replicas != nil && !hasRange:
+		schedule.Type = validator.TypeFixed
+	case schedule.Replicas == nil && hasRange:
+		schedule.Type = validator.TypeRange
+	}
+}
@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+)
+
+func TestDefaultScheduledScaler(t *testing.T) {
+	replica := int32(1)
+	min := int32(1)
+	max := int32(3)
+	tc := map[string]struct {
+		spec         scscv1.ScheduledScalerSpec
+		expectedSpec scscv1.ScheduledScalerSpec
+	}{
+		"every default is filled in": {
+			spec: scscv1.ScheduledScalerSpec{
+				Target: scscv1.SchedulingTarget{Name: "test-deploy"},
+				Schedule: []scscv1.Schedule{
+					{Runat: "0 0 9 * * *", Replicas: &replica},
+					{Runat: "0 0 18 * * *", MinReplicas: &min, MaxReplicas: &max},
+				},
+				Default: &scscv1.Schedule{MinReplicas: &min, MaxReplicas: &max},
+			},
+			expectedSpec: scscv1.ScheduledScalerSpec{
+				TimeZone: "Asia/Seoul",
+				Target:   scscv1.SchedulingTarget{Kind: "Deployment", APIVersion: "apps/v1", Name: "test-deploy"},
+				Schedule: []scscv1.Schedule{
+					{Type: "fixed", Runat: "0 0 9 * * *", Replicas: &replica},
+					{Type: "range", Runat: "0 0 18 * * *", MinReplicas: &min, MaxReplicas: &max},
+				},
+				Default: &scscv1.Schedule{Type: "range", MinReplicas: &min, MaxReplicas: &max},
+			},
+		},
+		"given values are kept": {
+			spec: scscv1.ScheduledScalerSpec{
+				TimeZone: "UTC",
+				Target:   scscv1.SchedulingTarget{Kind: "StatefulSet", APIVersion: "apps/v1", Name: "test-sts"},
+				Schedule: []scscv1.Schedule{
+					{Type: "range", Runat: "0 0 9 * * *", Replicas: &replica},
+				},
+			},
+			expectedSpec: scscv1.ScheduledScalerSpec{
+				TimeZone: "UTC",
+				Target:   scscv1.SchedulingTarget{Kind: "StatefulSet", APIVersion: "apps/v1", Name: "test-sts"},
+				Schedule: []scscv1.Schedule{
+					{Type: "range", Runat: "0 0 9 * * *", Replicas: &replica},
+				},
+			},
+		},
+		"ambiguous type and time zone of calendar are left": {
+			spec: scscv1.ScheduledScalerSpec{
+				Target:      scscv1.SchedulingTarget{Name: "test-deploy"},
+				CalendarRef: &scscv1.CalendarReference{Name: "test-calendar"},
+				Schedule: []scscv1.Schedule{
+					{Runat: "0 0 9 * * *", Replicas: &replica, MinReplicas: &min},
+				},
+			},
+			expectedSpec: scscv1.ScheduledScalerSpec{
+				Target:      scscv1.SchedulingTarget{Kind: "Deployment", APIVersion: "apps/v1", Name: "test-deploy"},
+				CalendarRef: &scscv1.CalendarReference{Name: "test-calendar"},
+				Schedule: []scscv1.Schedule{
+					{Runat: "0 0 9 * * *", Replicas: &replica, MinReplicas: &min},
+				},
+			},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			scsc := &scscv1.ScheduledScaler{Spec: c.spec}
+
+			// do testing function
+			DefaultScheduledScaler(scsc, "Asia/Seoul")
+
+			// verify by cases
+			require.Equal(t, c.expectedSpec, scsc.Spec)
+		})
+	}
+}
+
+func TestDefaultScheduleCalendar(t *testing.T) {
+	// set test case
+	replica := int32(1)
+	calendar := &scscv1.ScheduleCalendar{Spec: scscv1.ScheduleCalendarSpec{
+		Schedule: []scscv1.Schedule{
+			{Name: "business-hours", Start: "0 0 9 * * 1-5", End: "0 0 18 * * 1-5", Replicas: &replica},
+		},
+	}}
+
+	// do testing function
+	DefaultScheduleCalendar(calendar, "Asia/Seoul")
+
+	// verify by cases
+	require.Equal(t, "Asia/Seoul", calendar.Spec.TimeZone)
+	require.Equal(t, "fixed", calendar.Spec.Schedule[0].Type)
+}
@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// CalendarMutatePath is the path where ScheduleCalendarDefaulter serves
+const CalendarMutatePath = "/mutate-tmax-io-v1-schedulecalendar"
+
+// +kubebuilder:webhook:path=/mutate-tmax-io-v1-schedulecalendar,mutating=true,failurePolicy=fail,groups=tmax.io,resources=schedulecalendars,verbs=create;update,versions=v1,name=mschedulecalendar.tmax.io
+
+// ScheduleCalendarDefaulter fills in defaults of ScheduleCalendar, because its schedules are merged into ScheduledScalers
+type ScheduleCalendarDefaulter struct {
+	// DefaultTimeZone is the time zone of calendars which have no time zone. It's cluster-wide
+	DefaultTimeZone string
+	decoder         *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &ScheduleCalendarDefaulter{}
+
+// SetupWithManager registers the defaulter to the webhook server of the manager
+func (d *ScheduleCalendarDefaulter) SetupWithManager(mgr ctrl.Manager) {
+	mgr.GetWebhookServer().Register(CalendarMutatePath, &webhook.Admission{Handler: d})
+}
+
+// InjectDecoder injects the decoder of the manager's scheme
+func (d *ScheduleCalendarDefaulter) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+// Handle patches the ScheduleCalendar with defaults
+func (d *ScheduleCalendarDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	calendar := &scscv1.ScheduleCalendar{}
+	if err := d.decoder.Decode(req, calendar); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	DefaultScheduleCalendar(calendar, d.DefaultTimeZone)
+	return patchResponse(req, calendar)
+}
@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	"github.com/tmax-cloud/scheduled-scaler-operator/pkg/apimanager"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Paths where webhooks of ScheduledScaler serve
+const (
+	ValidatePath = "/validate-tmax-io-v1-scheduledscaler"
+	MutatePath   = "/mutate-tmax-io-v1-scheduledscaler"
+)
+
+var logger = logf.Log.WithName("webhook")
+
+// +kubebuilder:webhook:path=/mutate-tmax-io-v1-scheduledscaler,mutating=true,failurePolicy=fail,groups=tmax.io,resources=scheduledscalers,verbs=create;update,versions=v1,name=mscheduledscaler.tmax.io
+
+// ScheduledScalerDefaulter fills in defaults of ScheduledScaler, so that users see the effective spec
+type ScheduledScalerDefaulter struct {
+	// DefaultTimeZone is the time zone of ScheduledScalers which have neither time zone nor calendar. It's cluster-wide
+	DefaultTimeZone string
+	decoder         *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &ScheduledScalerDefaulter{}
+
+// SetupWithManager registers the defaulter to the webhook server of the manager
+func (d *ScheduledScalerDefaulter) SetupWithManager(mgr ctrl.Manager) {
+	mgr.GetWebhookServer().Register(MutatePath, &webhook.Admission{Handler: d})
+}
+
+// InjectDecoder injects the decoder of the manager's scheme
+func (d *ScheduledScalerDefaulter) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+// Handle patches the ScheduledScaler with defaults
+func (d *ScheduledScalerDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	scsc := &scscv1.ScheduledScaler{}
+	if err := d.decoder.Decode(req, scsc); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	DefaultScheduledScaler(scsc, d.DefaultTimeZone)
+	return patchResponse(req, scsc)
+}
+
+// patchResponse returns patches from the object of the request to the defaulted object
+func patchResponse(req admission.Request, defaulted interface{}) admission.Response {
+	marshaled, err := json.Marshal(defaulted)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// +kubebuilder:webhook:path=/validate-tmax-io-v1-scheduledscaler,mutating=false,failurePolicy=fail,groups=tmax.io,resources=scheduledscalers,verbs=create;update,versions=v1,name=vscheduledscaler.tmax.io
+
+// ScheduledScalerValidator rejects invalid ScheduledScaler at apply time, with errors of every invalid field.
+// It validates with the same validator as the controller
+type ScheduledScalerValidator struct {
+	decoder *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &ScheduledScalerValidator{}
+
+// SetupWithManager registers the validator to the webhook server of the manager
+func (v *ScheduledScalerValidator) SetupWithManager(mgr ctrl.Manager) {
+	mgr.GetWebhookServer().Register(ValidatePath, &webhook.Admission{Handler: v})
+}
+
+// InjectDecoder injects the decoder of the manager's scheme
+func (v *ScheduledScalerValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle allows the ScheduledScaler only if its spec is valid. Updates which don't change the spec are always allowed
+func (v *ScheduledScalerValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	scsc := &scscv1.ScheduledScaler{}
+	if err := v.decoder.Decode(req, scsc); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1beta1.Update {
+		old := &scscv1.ScheduledScaler{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		// finalizers of ScheduledScaler created before the webhook must be removable even if its spec is invalid
+		if !scsc.DeletionTimestamp.IsZero() || equality.Semantic.DeepEqual(old.Spec, scsc.Spec) {
+			return admission.Allowed("")
+		}
+	}
+
+	errs := apimanager.Validate(scsc)
+	if len(errs) == 0 {
+		return admission.Allowed("")
+	}
+
+	logger.Info("Rejected invalid ScheduledScaler", "namespace", req.Namespace, "name", req.Name, "errors", errs.ToAggregate().Error())
+	invalid := errors.NewInvalid(scscv1.GroupVersion.WithKind("ScheduledScaler").GroupKind(), req.Name, errs)
+	return admission.Response{
+		AdmissionResponse: admissionv1beta1.AdmissionResponse{
+			Allowed: false,
+			Result:  &invalid.ErrStatus,
+		},
+	}
+}
@@ -0,0 +1,159 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	scscv1 "github.com/tmax-cloud/scheduled-scaler-operator/api/v1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestScheduledScalerValidator_Handle(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+
+	replica := int32(1)
+	min := int32(3)
+	max := int32(1)
+	valid := scscv1.ScheduledScalerSpec{
+		Target: scscv1.SchedulingTarget{Name: "test-deploy"},
+		Schedule: []scscv1.Schedule{
+			{Type: "fixed", Runat: "0 0 9 * * *", Replicas: &replica},
+		},
+	}
+	invalid := scscv1.ScheduledScalerSpec{
+		Target: scscv1.SchedulingTarget{Name: "test-deploy"},
+		Schedule: []scscv1.Schedule{
+			{Type: "fixed", Runat: "0 0 9 * * *", Replicas: &replica},
+			{Type: "range", Runat: "0 0 18 * * *", MinReplicas: &min, MaxReplicas: &max},
+		},
+	}
+	deleted := metav1.NewTime(time.Now())
+	tc := map[string]struct {
+		operation       admissionv1beta1.Operation
+		spec            scscv1.ScheduledScalerSpec
+		oldSpec         *scscv1.ScheduledScalerSpec
+		deletion        *metav1.Time
+		expectedAllowed bool
+		expectedMessage string
+	}{
+		"create valid": {
+			operation:       admissionv1beta1.Create,
+			spec:            valid,
+			expectedAllowed: true,
+		},
+		"create invalid": {
+			operation:       admissionv1beta1.Create,
+			spec:            invalid,
+			expectedMessage: `ScheduledScaler.tmax.io "test-scsc" is invalid: spec.schedule[1].maxReplicas: Invalid value: 1: must be >= minReplicas`,
+		},
+		"update to invalid": {
+			operation:       admissionv1beta1.Update,
+			spec:            invalid,
+			oldSpec:         &valid,
+			expectedMessage: `ScheduledScaler.tmax.io "test-scsc" is invalid: spec.schedule[1].maxReplicas: Invalid value: 1: must be >= minReplicas`,
+		},
+		"update invalid without changing spec": {
+			operation:       admissionv1beta1.Update,
+			spec:            invalid,
+			oldSpec:         &invalid,
+			expectedAllowed: true,
+		},
+		"update invalid in deleting": {
+			operation:       admissionv1beta1.Update,
+			spec:            invalid,
+			oldSpec:         &valid,
+			deletion:        &deleted,
+			expectedAllowed: true,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// set test case
+			decoder, err := admission.NewDecoder(s)
+			require.NoError(t, err)
+			testValidator := &ScheduledScalerValidator{}
+			require.NoError(t, testValidator.InjectDecoder(decoder))
+
+			req := admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+				Operation: c.operation,
+				Name:      "test-scsc",
+				Namespace: "test-ns",
+				Object:    rawScheduledScaler(t, c.spec, c.deletion),
+			}}
+			if c.oldSpec != nil {
+				req.OldObject = rawScheduledScaler(t, *c.oldSpec, nil)
+			}
+
+			// do testing function
+			res := testValidator.Handle(context.TODO(), req)
+
+			// verify by cases
+			require.Equal(t, c.expectedAllowed, res.Allowed)
+			if !c.expectedAllowed {
+				require.Equal(t, int32(http.StatusUnprocessableEntity), res.Result.Code)
+				require.Equal(t, c.expectedMessage, res.Result.Message)
+			}
+		})
+	}
+}
+
+func TestScheduledScalerDefaulter_Handle(t *testing.T) {
+	// set test case
+	s := runtime.NewScheme()
+	utilruntime.Must(scscv1.AddToScheme(s))
+	decoder, err := admission.NewDecoder(s)
+	require.NoError(t, err)
+	testDefaulter := &ScheduledScalerDefaulter{DefaultTimeZone: "Asia/Seoul"}
+	require.NoError(t, testDefaulter.InjectDecoder(decoder))
+
+	replica := int32(1)
+	req := admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+		Operation: admissionv1beta1.Create,
+		Name:      "test-scsc",
+		Namespace: "test-ns",
+		Object: rawScheduledScaler(t, scscv1.ScheduledScalerSpec{
+			TimeZone: "UTC",
+			Target:   scscv1.SchedulingTarget{Kind: "Deployment", APIVersion: "apps/v1", Name: "test-deploy"},
+			Schedule: []scscv1.Schedule{
+				{Runat: "0 0 9 * * *", Replicas: &replica},
+			},
+		}, nil),
+	}}
+
+	// do testing function
+	res := testDefaulter.Handle(context.TODO(), req)
+
+	// verify by cases
+	require.True(t, res.Allowed)
+	require.Len(t, res.Patches, 1)
+	require.Equal(t, "add", res.Patches[0].Operation)
+	require.Equal(t, "/spec/schedule/0/type", res.Patches[0].Path)
+	require.Equal(t, "fixed", res.Patches[0].Value)
+}
+
+func rawScheduledScaler(t *testing.T, spec scscv1.ScheduledScalerSpec, deletion *metav1.Time) runtime.RawExtension {
+	raw, err := json.Marshal(&scscv1.ScheduledScaler{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: scscv1.GroupVersion.String(),
+			Kind:       "ScheduledScaler",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-scsc",
+			Namespace:         "test-ns",
+			DeletionTimestamp: deletion,
+		},
+		Spec: spec,
+	})
+	require.NoError(t, err)
+	return runtime.RawExtension{Raw: raw}
+}